@@ -0,0 +1,215 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mintoolkit/mint/pkg/app/event"
+)
+
+// Topic names for the event broker. They line up with the keys Output
+// already stamps into its `msg` maps (cmd, state/info/error, exit.code, etc).
+const (
+	TopicState = "state"
+	TopicInfo  = "info"
+	TopicData  = "data"
+	TopicLog   = "log"
+	TopicError = "error"
+)
+
+// replayBufferSize is how many recent events per topic are kept around so a
+// late-joining subscriber can call replayLast() instead of starting blind.
+const replayBufferSize = 100
+
+// Event is a single broker-routed message. Data is the event.Envelope an
+// Output method emitted (Topic from topicForEnvelope), a legacy `msg` map
+// (Topic from topicFor), or the raw value passed to Output.Data (TopicData).
+type Event struct {
+	Topic string
+	Data  interface{}
+}
+
+// Subscription is a live subscriber registration returned by Broker.Subscribe.
+// Events for topics not in Topics are never sent to Ch. When the consumer
+// can't keep up, the broker applies the subscription's Drop policy instead
+// of blocking the publisher.
+type Subscription struct {
+	ID     string
+	Topics map[string]struct{}
+	Ch     chan Event
+	Drop   DropPolicy
+}
+
+// DropPolicy controls what the broker does when a subscriber's channel is
+// full.
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest buffered event for this subscriber to
+	// make room for the new one (default: favors fresh state over history).
+	DropOldest DropPolicy = iota
+	// DropNewest discards the incoming event instead of anything buffered.
+	DropNewest
+)
+
+// Broker fans internal Output events out to any number of typed subscribers
+// (e.g. the JSON-RPC subscription transport in pkg/app/subscribe), each with
+// its own topic filter and backpressure policy, plus a per-topic replay
+// buffer and last-known-state snapshot for late joiners.
+type Broker struct {
+	mu          sync.Mutex
+	subs        map[string]*Subscription
+	replay      map[string][]interface{}
+	lastByTopic map[string]interface{}
+	nextID      int
+}
+
+// NewBroker creates an empty Broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subs:        map[string]*Subscription{},
+		replay:      map[string][]interface{}{},
+		lastByTopic: map[string]interface{}{},
+	}
+}
+
+// Subscribe registers a new subscriber for the given topics (all topics if
+// empty) and returns its Subscription, including a buffered channel the
+// caller should range over.
+func (b *Broker) Subscribe(topics []string, drop DropPolicy) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	sub := &Subscription{
+		ID:     fmt.Sprintf("%s-%d", time.Now().UTC().Format("20060102T150405.000000000"), b.nextID),
+		Topics: map[string]struct{}{},
+		Ch:     make(chan Event, replayBufferSize),
+		Drop:   drop,
+	}
+
+	for _, t := range topics {
+		sub.Topics[t] = struct{}{}
+	}
+
+	b.subs[sub.ID] = sub
+	return sub
+}
+
+// Unsubscribe removes a subscription and closes its channel.
+func (b *Broker) Unsubscribe(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, ok := b.subs[id]; ok {
+		delete(b.subs, id)
+		close(sub.Ch)
+	}
+}
+
+// Publish routes data to every subscriber interested in topic, recording it
+// in the topic's replay buffer and last-known-state snapshot first.
+func (b *Broker) Publish(topic string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastByTopic[topic] = data
+
+	buf := append(b.replay[topic], data)
+	if len(buf) > replayBufferSize {
+		buf = buf[len(buf)-replayBufferSize:]
+	}
+	b.replay[topic] = buf
+
+	ev := Event{Topic: topic, Data: data}
+	for _, sub := range b.subs {
+		if len(sub.Topics) > 0 {
+			if _, want := sub.Topics[topic]; !want {
+				continue
+			}
+		}
+
+		select {
+		case sub.Ch <- ev:
+		default:
+			switch sub.Drop {
+			case DropNewest:
+				//slow subscriber: drop the event we just tried to send
+			default: //DropOldest
+				select {
+				case <-sub.Ch:
+				default:
+				}
+				select {
+				case sub.Ch <- ev:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// ReplayLast returns up to n of the most recent events published for topic;
+// n <= 0 returns every event still in the topic's replay buffer.
+func (b *Broker) ReplayLast(topic string, n int) []interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buf := b.replay[topic]
+	if n <= 0 || n > len(buf) {
+		n = len(buf)
+	}
+
+	out := make([]interface{}, n)
+	copy(out, buf[len(buf)-n:])
+	return out
+}
+
+// GetState returns the last published value for every topic that has seen
+// at least one event.
+func (b *Broker) GetState() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := make(map[string]interface{}, len(b.lastByTopic))
+	for k, v := range b.lastByTopic {
+		state[k] = v
+	}
+
+	return state
+}
+
+// topicFor derives the broker topic for a msg map the way Output already
+// shapes it (State/Info/Error all stamp a distinguishing key).
+func topicFor(msg map[string]string) string {
+	switch {
+	case msg["error"] != "":
+		return TopicError
+	case msg["state"] != "":
+		return TopicState
+	case msg["info"] != "":
+		return TopicInfo
+	case msg["log"] != "":
+		return TopicLog
+	default:
+		return TopicData
+	}
+}
+
+// topicForEnvelope derives the broker topic for an event.Envelope (every
+// Output method now publishes these, via emit, instead of the legacy msg map).
+func topicForEnvelope(e event.Envelope) string {
+	switch e.EventKind {
+	case event.KindError:
+		return TopicError
+	case event.KindState:
+		return TopicState
+	case event.KindInfo:
+		return TopicInfo
+	case event.KindLog:
+		return TopicLog
+	default:
+		return TopicData
+	}
+}
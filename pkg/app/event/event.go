@@ -0,0 +1,114 @@
+// Package event defines the structured envelope Output wraps every emitted
+// event in (state/info/error/prompt/log/data), plus pluggable encoders for
+// it, so new output formats (logfmt today, OpenTelemetry logs tomorrow) can
+// be added without touching each Output method.
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is bumped whenever Envelope's shape changes in a
+// backwards-incompatible way.
+const SchemaVersion = 1
+
+// Kind is the broad category of an emitted event.
+type Kind string
+
+const (
+	KindInfo   Kind = "info"
+	KindState  Kind = "state"
+	KindError  Kind = "error"
+	KindPrompt Kind = "prompt"
+	KindLog    Kind = "log"
+	KindData   Kind = "data"
+)
+
+// Envelope is the top-level shape of every event mint emits, independent of
+// the console/file encoding used to render it.
+type Envelope struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Ts            string                 `json:"ts"`
+	Cmd           string                 `json:"cmd"`
+	EventKind     Kind                   `json:"event_kind"`
+	EventType     string                 `json:"event_type"`
+	Attrs         map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// New builds an Envelope stamped with the current time in RFC3339Nano.
+func New(cmd string, kind Kind, eventType string, attrs map[string]interface{}) Envelope {
+	return Envelope{
+		SchemaVersion: SchemaVersion,
+		Ts:            time.Now().UTC().Format(time.RFC3339Nano),
+		Cmd:           cmd,
+		EventKind:     kind,
+		EventType:     eventType,
+		Attrs:         attrs,
+	}
+}
+
+// JSON marshals the envelope for the NDJSON event log and the 'json'
+// console format.
+func (e Envelope) JSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Logfmt renders the envelope as 'key=value' pairs (quoting values that
+// contain spaces), for the 'logfmt' console format and tools like
+// Loki/Vector that parse logfmt natively.
+func (e Envelope) Logfmt() string {
+	var b strings.Builder
+	writeLogfmtPair(&b, "schema_version", e.SchemaVersion)
+	writeLogfmtPair(&b, "ts", e.Ts)
+	writeLogfmtPair(&b, "cmd", e.Cmd)
+	writeLogfmtPair(&b, "event_kind", e.EventKind)
+	writeLogfmtPair(&b, "event_type", e.EventType)
+
+	keys := make([]string, 0, len(e.Attrs))
+	for k := range e.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		writeLogfmtPair(&b, k, e.Attrs[k])
+	}
+
+	return strings.TrimSuffix(b.String(), " ")
+}
+
+func writeLogfmtPair(b *strings.Builder, key string, value interface{}) {
+	val := fmt.Sprintf("%v", value)
+	if strings.ContainsAny(val, " \"") {
+		val = fmt.Sprintf("%q", val)
+	}
+
+	b.WriteString(key)
+	b.WriteString("=")
+	b.WriteString(val)
+	b.WriteString(" ")
+}
+
+// Encoder renders an Envelope as a single line of output text.
+type Encoder func(Envelope) string
+
+// encoders is the format-name -> Encoder registry Output dispatches through.
+var encoders = map[string]Encoder{
+	"json":   func(e Envelope) string { data, _ := e.JSON(); return string(data) },
+	"logfmt": Envelope.Logfmt,
+}
+
+// Register adds (or overrides) the Encoder used for format.
+func Register(format string, enc Encoder) {
+	encoders[format] = enc
+}
+
+// Get looks up the Encoder registered for format.
+func Get(format string) (Encoder, bool) {
+	enc, ok := encoders[format]
+	return enc, ok
+}
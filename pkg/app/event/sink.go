@@ -0,0 +1,142 @@
+package event
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sink is a persistent NDJSON event log, written to by Output whenever
+// --event-log is set, with size- and age-based rotation and gzip of rotated
+// segments for post-mortem / log-processor consumption.
+type Sink struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	maxSize  int64
+	maxAge   time.Duration
+}
+
+// NewSink opens (creating if needed) path for append and rotates it
+// according to maxSize (bytes, 0 disables size-based rotation) and maxAge
+// (0 disables age-based rotation).
+func NewSink(path string, maxSize int64, maxAge time.Duration) (*Sink, error) {
+	s := &Sink{
+		path:    path,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Sink) open() error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	s.file = f
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	return nil
+}
+
+// Write appends e's JSON encoding as one NDJSON line, rotating first if the
+// configured size/age threshold has been crossed.
+func (s *Sink) Write(e Envelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shouldRotate() {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	data, err := e.JSON()
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *Sink) shouldRotate() bool {
+	if s.maxSize > 0 && s.size >= s.maxSize {
+		return true
+	}
+
+	if s.maxAge > 0 && time.Since(s.openedAt) >= s.maxAge {
+		return true
+	}
+
+	return false
+}
+
+func (s *Sink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	rotatedPath := fmt.Sprintf("%s.%s", s.path, time.Now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(s.path, rotatedPath); err != nil {
+		return err
+	}
+
+	go gzipAndRemove(rotatedPath)
+
+	return s.open()
+}
+
+func gzipAndRemove(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		gw.Close()
+		return
+	}
+
+	if err := gw.Close(); err != nil {
+		return
+	}
+
+	os.Remove(path)
+}
+
+// Close flushes and closes the underlying file.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
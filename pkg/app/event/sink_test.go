@@ -0,0 +1,43 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSinkShouldRotate(t *testing.T) {
+	testCases := []struct {
+		name string
+		sink *Sink
+		want bool
+	}{
+		{
+			name: "under both thresholds",
+			sink: &Sink{size: 10, maxSize: 100, openedAt: time.Now(), maxAge: time.Hour},
+			want: false,
+		},
+		{
+			name: "size threshold crossed",
+			sink: &Sink{size: 200, maxSize: 100, openedAt: time.Now(), maxAge: time.Hour},
+			want: true,
+		},
+		{
+			name: "age threshold crossed",
+			sink: &Sink{size: 10, maxSize: 100, openedAt: time.Now().Add(-2 * time.Hour), maxAge: time.Hour},
+			want: true,
+		},
+		{
+			name: "zero thresholds disable rotation",
+			sink: &Sink{size: 1 << 30, maxSize: 0, openedAt: time.Now().Add(-24 * time.Hour), maxAge: 0},
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.sink.shouldRotate(); got != tc.want {
+				t.Errorf("shouldRotate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/mintoolkit/mint/pkg/app/event"
+	"github.com/mintoolkit/mint/pkg/app/notify"
+	"github.com/mintoolkit/mint/pkg/app/theme"
 	"github.com/mintoolkit/mint/pkg/consts"
 	"github.com/mintoolkit/mint/pkg/util/fsutil"
 	v "github.com/mintoolkit/mint/pkg/version"
@@ -19,16 +23,18 @@ const (
 	ofJSON         = "json"
 	ofText         = "text"
 	ofSubscription = "subscription"
+	ofLogfmt       = "logfmt"
 )
 
 type ExecutionContext struct {
 	Out             *Output
 	Args            []string
 	cleanupHandlers []func()
+	notifier        *notify.Notifier
+	pendingNotify   *notify.Event
 }
 
 func (ref *ExecutionContext) Exit(exitCode int) {
-	ref.doCleanup()
 	ref.exit(exitCode)
 }
 
@@ -52,6 +58,22 @@ func (ref *ExecutionContext) doCleanup() {
 	}
 }
 
+// dispatchPendingNotify is registered as the first cleanup handler (so it
+// runs last, after every other handler's cleanup work) and fires whatever
+// notify.Event FailOn/Fail/exit staged in ref.pendingNotify. Routing
+// notification through the cleanup-handler chain (instead of calling
+// ref.notify directly) means any termination path that ends up at doCleanup
+// still notifies sinks, not just the three call sites that set it up.
+func (ref *ExecutionContext) dispatchPendingNotify() {
+	if ref.pendingNotify == nil {
+		return
+	}
+
+	e := *ref.pendingNotify
+	ref.pendingNotify = nil
+	ref.notify(e)
+}
+
 func (ref *ExecutionContext) WarnOn(err error) {
 	if err != nil {
 		log.WithError(err).Debug("error.warning")
@@ -60,8 +82,6 @@ func (ref *ExecutionContext) WarnOn(err error) {
 
 func (ref *ExecutionContext) FailOn(err error) {
 	if err != nil {
-		ref.doCleanup()
-
 		//not using FailOn from errutil to control the flow/output better
 		stackData := debug.Stack()
 		log.WithError(err).WithFields(log.Fields{
@@ -72,13 +92,20 @@ func (ref *ExecutionContext) FailOn(err error) {
 			ref.Out.Info("fail.on", OutVars{"version": v.Current()})
 		}
 
-		ref.exit(-1)
+		ref.pendingNotify = &notify.Event{
+			Cmd:      ref.Out.CmdName,
+			Kind:     "fail",
+			Version:  v.Current(),
+			Location: fsutil.ExeDir(),
+			Stack:    string(stackData),
+		}
+
+		ref.doCleanup()
+		ref.terminate(-1)
 	}
 }
 
 func (ref *ExecutionContext) Fail(reason string) {
-	ref.doCleanup()
-
 	//not using FailOn from errutil to control the flow/output better
 	stackData := debug.Stack()
 	log.WithFields(log.Fields{
@@ -90,8 +117,17 @@ func (ref *ExecutionContext) Fail(reason string) {
 		ref.Out.Info("fail.on", OutVars{"version": v.Current()})
 	}
 
-	ShowCommunityInfo(ref.Out.OutputFormat)
-	ref.exit(-1)
+	ref.pendingNotify = &notify.Event{
+		Cmd:      ref.Out.CmdName,
+		Kind:     "fail",
+		Version:  v.Current(),
+		Location: fsutil.ExeDir(),
+		Stack:    string(stackData),
+		Extra:    map[string]interface{}{"reason": reason},
+	}
+
+	ref.doCleanup()
+	ref.terminate(-1)
 }
 
 func (ref *ExecutionContext) exit(exitCode int) {
@@ -104,10 +140,35 @@ func (ref *ExecutionContext) exit(exitCode int) {
 
 	}
 
-	ShowCommunityInfo(ref.Out.OutputFormat)
+	ref.pendingNotify = &notify.Event{
+		Cmd:      ref.Out.CmdName,
+		Kind:     "exit",
+		State:    "exited",
+		ExitCode: exitCode,
+		Version:  v.Current(),
+		Location: fsutil.ExeDir(),
+		Args:     os.Args,
+	}
+
+	ref.doCleanup()
+	ref.terminate(exitCode)
+}
+
+// terminate shows the community links and exits the process. It's shared by
+// FailOn/Fail (which dispatch a "fail" notify event) and exit (which
+// dispatches "exit") so a single termination never double-notifies sinks
+// with both kinds.
+func (ref *ExecutionContext) terminate(exitCode int) {
+	ShowCommunityInfo(ref.Out.OutputFormat, ref.Out.Theme)
 	os.Exit(exitCode)
 }
 
+func (ref *ExecutionContext) notify(e notify.Event) {
+	if ref.notifier != nil {
+		ref.notifier.Dispatch(e)
+	}
+}
+
 func NewExecutionContext(
 	cmdName string,
 	quiet bool,
@@ -119,10 +180,17 @@ func NewExecutionContext(
 	}
 
 	ref := &ExecutionContext{
-		Out:  NewOutput(cmdName, quiet, outputFormat, chs),
-		Args: os.Args,
+		Out:      NewOutput(cmdName, quiet, outputFormat, chs),
+		Args:     os.Args,
+		notifier: notify.NewNotifier(notify.ConfigPath()),
 	}
 
+	// Registered first so it runs last (doCleanup runs handlers in reverse
+	// order): every other cleanup handler's work (closing listeners,
+	// flushing the event log sink, stopping an ephemeral registry, ...)
+	// happens before sinks are notified.
+	ref.AddCleanupHandler(ref.dispatchPendingNotify)
+
 	return ref
 }
 
@@ -131,6 +199,9 @@ type Output struct {
 	Quiet          bool
 	OutputFormat   string
 	DataChannels   map[string]chan interface{}
+	Broker         *Broker
+	Theme          *theme.Theme
+	eventSink      *event.Sink
 	internalDataCh chan interface{}
 }
 
@@ -140,11 +211,14 @@ func NewOutput(cmdName string, quiet bool, outputFormat string, channels map[str
 		Quiet:          quiet,
 		OutputFormat:   outputFormat,
 		DataChannels:   channels,
+		Broker:         NewBroker(),
+		Theme:          theme.Resolve(""),
 		internalDataCh: make(chan interface{}),
 	}
 
-	// We want to listen to the internal channel for any data
-	// And dump it onto the appropriate DataChannels
+	// We want to listen to the internal channel for any data, dump it onto
+	// the appropriate DataChannels, and fan it out to any live Broker
+	// subscribers (e.g. the JSON-RPC subscription transport).
 	go func() {
 		for data := range ref.internalDataCh {
 			log.Debugf("execontext internal data: %v\n", data)
@@ -152,6 +226,15 @@ func NewOutput(cmdName string, quiet bool, outputFormat string, channels map[str
 				for _, ch := range ref.DataChannels {
 					ch <- data
 				}
+
+				switch v := data.(type) {
+				case map[string]string:
+					ref.Broker.Publish(topicFor(v), data)
+				case event.Envelope:
+					ref.Broker.Publish(topicForEnvelope(v), data)
+				default:
+					ref.Broker.Publish(TopicData, data)
+				}
 			}
 		}
 	}()
@@ -163,44 +246,92 @@ func NoColor() {
 	color.NoColor = true
 }
 
+// SetTheme overrides the color theme used for all subsequent colorized
+// output (the '--theme'/MINT_THEME resolved theme is used by default).
+func (ref *Output) SetTheme(t *theme.Theme) {
+	if t != nil {
+		ref.Theme = t
+	}
+}
+
+// SetEventLog points Output at a persistent NDJSON event log (enabled with
+// '--event-log <path>'). Every event.Envelope emitted afterwards is appended
+// to it, independent of OutputFormat, for post-mortem / log-processor use.
+func (ref *Output) SetEventLog(path string, maxSizeBytes int64, maxAge time.Duration) error {
+	sink, err := event.NewSink(path, maxSizeBytes, maxAge)
+	if err != nil {
+		return err
+	}
+
+	ref.eventSink = sink
+	return nil
+}
+
+// emit builds the Envelope for kind/eventType/attrs, writes it to the event
+// log sink (if configured), fans it out to any live Broker subscribers
+// (independent of OutputFormat - a command doesn't have to run with
+// '--output subscription' for 'mint.subscribe' clients to see its events),
+// and returns it for the caller's OutputFormat switch to encode/render.
+func (ref *Output) emit(kind event.Kind, eventType string, attrs map[string]interface{}) event.Envelope {
+	e := event.New(ref.CmdName, kind, eventType, attrs)
+	if ref.eventSink != nil {
+		if err := ref.eventSink.Write(e); err != nil {
+			log.WithError(err).Debug("event.sink.write")
+		}
+	}
+
+	ref.internalDataCh <- e
+	return e
+}
+
+// encodeEnvelope renders e using the Encoder registered for ref.OutputFormat,
+// falling back to JSON if none is registered (e.g. when called for 'text').
+func (ref *Output) encodeEnvelope(e event.Envelope) string {
+	if enc, ok := event.Get(ref.OutputFormat); ok {
+		return enc(e)
+	}
+
+	data, _ := e.JSON()
+	return string(data)
+}
+
 type OutVars map[string]interface{}
 
 func (ref *Output) LogDump(logType string, data string, params ...OutVars) {
-	if ref.Quiet {
+	if ref.Quiet && ref.OutputFormat != ofSubscription {
 		return
 	}
 
 	var info string
-	msg := map[string]string{}
-	var jsonData []byte
-
-	msg["cmd"] = ref.CmdName
-	msg["log"] = logType
-	msg["data"] = data
+	attrs := OutVars{"data": data}
 
 	if len(params) > 0 {
 		kvSet := params[0]
 		if len(kvSet) > 0 {
 			var builder strings.Builder
 			for k, v := range kvSet {
-				msg[k] = fmt.Sprintf("%v", v)
-				builder.WriteString(kcolor(k))
+				attrs[k] = v
+				builder.WriteString(ref.Theme.Sprint(theme.RoleKey, k))
 				builder.WriteString("=")
-				builder.WriteString(fmt.Sprintf("'%s'", vcolor("%v", v)))
+				builder.WriteString(fmt.Sprintf("'%s'", ref.Theme.Sprintf(theme.RoleValue, "%v", v)))
 				builder.WriteString(" ")
 			}
 
 			info = builder.String()
 		}
 	}
+
 	switch ref.OutputFormat {
-	case ofJSON:
-		jsonData, _ = json.Marshal(msg)
-		fmt.Println(string(jsonData))
+	case ofJSON, ofLogfmt:
+		e := ref.emit(event.KindLog, logType, attrs)
+		fmt.Println(ref.encodeEnvelope(e))
 	case ofText:
+		ref.emit(event.KindLog, logType, attrs)
 		fmt.Printf("cmd=%s log='%s' event=LOG.START %s ====================\n", ref.CmdName, logType, info)
 		fmt.Println(data)
 		fmt.Printf("cmd=%s log='%s' event=LOG.END %s ====================\n", ref.CmdName, logType, info)
+	case ofSubscription:
+		ref.emit(event.KindLog, logType, attrs) // no console output; subscribers get it via the Broker
 	default:
 		log.Fatalf("Unknown console output flag: %s\n. It should be either 'text' or 'json", ref.OutputFormat)
 	}
@@ -208,27 +339,24 @@ func (ref *Output) LogDump(logType string, data string, params ...OutVars) {
 }
 
 func (ref *Output) Prompt(data string) {
-	if ref.Quiet {
+	if ref.Quiet && ref.OutputFormat != ofSubscription {
 		return
 	}
 
 	switch ref.OutputFormat {
-	case ofJSON:
-		//marshal data to json
-		var jsonData []byte
+	case ofJSON, ofLogfmt:
 		if len(data) > 0 {
-			msg := map[string]string{
-				"cmd":    ref.CmdName,
-				"prompt": data,
-			}
-			jsonData, _ = json.Marshal(msg)
-			fmt.Println(string(jsonData))
+			e := ref.emit(event.KindPrompt, "prompt", OutVars{"prompt": data})
+			fmt.Println(ref.encodeEnvelope(e))
 		}
 	case ofText:
-		color.Set(color.FgHiRed)
+		ref.emit(event.KindPrompt, "prompt", OutVars{"prompt": data})
+		ref.Theme.Set(theme.RolePrompt)
 		defer color.Unset()
 
 		fmt.Printf("cmd=%s prompt='%s'\n", ref.CmdName, data)
+	case ofSubscription:
+		ref.emit(event.KindPrompt, "prompt", OutVars{"prompt": data})
 	default:
 		log.Fatalf("Unknown console output flag: %s\n. It should be either 'text' or 'json", ref.OutputFormat)
 	}
@@ -236,28 +364,24 @@ func (ref *Output) Prompt(data string) {
 }
 
 func (ref *Output) Error(errType string, data string) {
-	if ref.Quiet {
+	if ref.Quiet && ref.OutputFormat != ofSubscription {
 		return
 	}
 
 	switch ref.OutputFormat {
-	case ofJSON:
-		//marshal data to json
-		var jsonData []byte
+	case ofJSON, ofLogfmt:
 		if len(data) > 0 {
-			msg := map[string]string{
-				"cmd":     ref.CmdName,
-				"error":   errType,
-				"message": data,
-			}
-			jsonData, _ = json.Marshal(msg)
-			fmt.Println(string(jsonData))
+			e := ref.emit(event.KindError, errType, OutVars{"message": data})
+			fmt.Println(ref.encodeEnvelope(e))
 		}
 	case ofText:
-		color.Set(color.FgHiRed)
+		ref.emit(event.KindError, errType, OutVars{"message": data})
+		ref.Theme.Set(theme.RoleError)
 		defer color.Unset()
 
 		fmt.Printf("cmd=%s error=%s message='%s'\n", ref.CmdName, errType, data)
+	case ofSubscription:
+		ref.emit(event.KindError, errType, OutVars{"message": data}) // no console output; subscribers get it via the Broker
 	default:
 		log.Fatalf("Unknown console output flag: %s\n. It should be either 'text' or 'json", ref.OutputFormat)
 	}
@@ -265,40 +389,40 @@ func (ref *Output) Error(errType string, data string) {
 }
 
 func (ref *Output) Message(data string) {
-	if ref.Quiet {
+	if ref.Quiet && ref.OutputFormat != ofSubscription {
 		return
 	}
 
 	switch ref.OutputFormat {
-	case ofJSON:
-		//marshal data to json
-		var jsonData []byte
+	case ofJSON, ofLogfmt:
 		if len(data) > 0 {
-			msg := map[string]string{
-				"cmd":     ref.CmdName,
-				"message": data,
-			}
-			jsonData, _ = json.Marshal(msg)
-			fmt.Println(string(jsonData))
+			e := ref.emit(event.KindInfo, "message", OutVars{"message": data})
+			fmt.Println(ref.encodeEnvelope(e))
 		}
 	case ofText:
-		color.Set(color.FgHiMagenta)
+		ref.emit(event.KindInfo, "message", OutVars{"message": data})
+		ref.Theme.Set(theme.RoleMessage)
 		defer color.Unset()
 
 		fmt.Printf("cmd=%s message='%s'\n", ref.CmdName, data)
+	case ofSubscription:
+		ref.emit(event.KindInfo, "message", OutVars{"message": data})
 	default:
 		log.Fatalf("Unknown console output flag: %s\n. It should be either 'text' or 'json", ref.OutputFormat)
 	}
 
 }
 
+// Data sends data to the named DataChannel (if one was registered for
+// channelKey) and always publishes it on the Broker's "data" topic, so
+// 'mint.subscribe' clients see build/command output streams even when the
+// caller never wired up a DataChannel for them.
 func (ref *Output) Data(channelKey string, data interface{}) {
 	if ch, exists := ref.DataChannels[channelKey]; exists {
 		ch <- data // Send data to the corresponding channel
-		fmt.Printf("Data sent to channel '%s': %v\n", channelKey, data)
-	} else {
-		fmt.Printf("Channel for channelKey '%s' not found\n", channelKey)
 	}
+
+	ref.Broker.Publish(TopicData, data)
 }
 
 func (ref *Output) State(state string, params ...OutVars) {
@@ -309,10 +433,7 @@ func (ref *Output) State(state string, params ...OutVars) {
 	var exitInfo string
 	var info string
 	var sep string
-	msg := map[string]string{}
-	var jsonData []byte
-	msg["cmd"] = ref.CmdName
-	msg["state"] = state
+	attrs := OutVars{}
 
 	if len(params) > 0 {
 		var minCount int
@@ -320,6 +441,7 @@ func (ref *Output) State(state string, params ...OutVars) {
 		if exitCode, ok := kvSet["exit.code"]; ok {
 			minCount = 1
 			exitInfo = fmt.Sprintf(" code=%d", exitCode)
+			attrs["exit.code"] = exitCode
 		}
 
 		if len(kvSet) > minCount {
@@ -330,8 +452,7 @@ func (ref *Output) State(state string, params ...OutVars) {
 				if k == "exit.code" {
 					continue
 				}
-				msg["exit.info"] = exitInfo
-				msg[k] = fmt.Sprintf("%v", v)
+				attrs[k] = v
 				builder.WriteString(k)
 				builder.WriteString("=")
 				val := fmt.Sprintf("%v", v)
@@ -347,33 +468,27 @@ func (ref *Output) State(state string, params ...OutVars) {
 		}
 	}
 
+	e := ref.emit(event.KindState, state, attrs)
+
 	switch ref.OutputFormat {
-	case ofJSON:
-		jsonData, _ = json.Marshal(msg)
-		fmt.Println(string(jsonData))
+	case ofJSON, ofLogfmt:
+		fmt.Println(ref.encodeEnvelope(e))
 	case ofText:
 		if state == "exited" || strings.Contains(state, "error") {
-			color.Set(color.FgHiRed, color.Bold)
+			ref.Theme.Set(theme.RoleStateError)
 		} else {
-			color.Set(color.FgCyan, color.Bold)
+			ref.Theme.Set(theme.RoleStateOK)
 		}
 		defer color.Unset()
 
 		fmt.Printf("cmd=%s state=%s%s%s%s\n", ref.CmdName, state, exitInfo, sep, info)
 	case ofSubscription:
-		ref.internalDataCh <- msg // Send data to the internal channel
-
+		// no console output; emit() already published e to the Broker above
 	default:
 		log.Fatalf("Unknown console output flag: %s\n. It should be either 'text' or 'json", ref.OutputFormat)
 	}
 }
 
-var (
-	itcolor = color.New(color.FgMagenta, color.Bold).SprintFunc()
-	kcolor  = color.New(color.FgHiGreen, color.Bold).SprintFunc()
-	vcolor  = color.New(color.FgHiBlue).SprintfFunc()
-)
-
 func (ref *Output) Info(infoType string, params ...OutVars) {
 	// TODO - carry this pattern to other Output methods
 	if ref.Quiet && ref.OutputFormat != ofSubscription {
@@ -382,10 +497,7 @@ func (ref *Output) Info(infoType string, params ...OutVars) {
 
 	var data string
 	var sep string
-	msg := map[string]string{}
-	var jsonData []byte
-	msg["cmd"] = ref.CmdName
-	msg["info"] = infoType
+	attrs := OutVars{}
 
 	if len(params) > 0 {
 		kvSet := params[0]
@@ -394,10 +506,10 @@ func (ref *Output) Info(infoType string, params ...OutVars) {
 			sep = " "
 
 			for k, v := range kvSet {
-				msg[k] = fmt.Sprintf("%v", v)
-				builder.WriteString(kcolor(k))
+				attrs[k] = v
+				builder.WriteString(ref.Theme.Sprint(theme.RoleKey, k))
 				builder.WriteString("=")
-				builder.WriteString(fmt.Sprintf("'%s'", vcolor("%v", v)))
+				builder.WriteString(fmt.Sprintf("'%s'", ref.Theme.Sprintf(theme.RoleValue, "%v", v)))
 				builder.WriteString(" ")
 			}
 
@@ -405,21 +517,22 @@ func (ref *Output) Info(infoType string, params ...OutVars) {
 		}
 	}
 
+	e := ref.emit(event.KindInfo, infoType, attrs)
+
 	switch ref.OutputFormat {
-	case ofJSON:
-		jsonData, _ = json.Marshal(msg)
-		fmt.Println(string(jsonData))
+	case ofJSON, ofLogfmt:
+		fmt.Println(ref.encodeEnvelope(e))
 	case ofText:
-		fmt.Printf("cmd=%s info=%s%s%s\n", ref.CmdName, itcolor(infoType), sep, data)
+		fmt.Printf("cmd=%s info=%s%s%s\n", ref.CmdName, ref.Theme.Sprint(theme.RoleInfoType, infoType), sep, data)
 	case ofSubscription:
-		ref.internalDataCh <- msg // Send data to the internal channel
+		// no console output; emit() already published e to the Broker above
 	default:
 		log.Fatalf("Unknown console output flag: %s\n. It should be either 'text' or 'json", ref.OutputFormat)
 	}
 
 }
 
-func ShowCommunityInfo(outputFormat string) {
+func ShowCommunityInfo(outputFormat string, t *theme.Theme) {
 	lines := []struct {
 		App     string `json:"app"`
 		Message string `json:"message"`
@@ -449,7 +562,7 @@ func ShowCommunityInfo(outputFormat string) {
 			fmt.Println(string(jsonData))
 		}
 	default:
-		color.Set(color.FgHiMagenta)
+		t.Set(theme.RoleCommunity)
 		defer color.Unset()
 
 		for _, v := range lines {
@@ -0,0 +1,66 @@
+// Package command holds the types and constants shared by every mint
+// sub-command (imagebuild, registry push, ...), independent of which
+// specific command is running.
+package command
+
+import "time"
+
+// AppName is the program name used in logging and notifications.
+const AppName = "mint"
+
+// Exit code bit flags. A command's final process exit code is built by
+// OR-ing an *ECT (exit code type) value with one or more *ECC (exit code
+// cause) values, so callers can tell "what kind of command failed" apart
+// from "why it failed" without a combinatorial list of exit codes.
+const (
+	ECTCommon = 0x0100
+
+	ECCNoDockerConnectInfo = 0x0001
+)
+
+// FlagTheme is the global CLI flag (registered once on the root command,
+// shared by every sub-command) that overrides the active color theme; its
+// value lands in GenericParams.Theme and is resolved via theme.Resolve.
+const (
+	FlagTheme      = "theme"
+	FlagThemeUsage = "color theme to use ('default', 'dark', 'light', 'mono', 'solarized', or a path to a theme file)"
+)
+
+// DockerClientConfig carries the Docker connection settings a command needs
+// to build its docker client, as parsed from the shared Docker CLI flags
+// (host, TLS, and env overrides).
+type DockerClientConfig struct {
+	Host        string
+	UseTLS      bool
+	VerifyTLS   bool
+	TLSCertPath string
+	Env         map[string]string
+}
+
+// GenericParams holds the CLI flags and derived state shared by every mint
+// command, independent of which specific sub-command is running.
+type GenericParams struct {
+	CheckVersion bool
+	InContainer  bool
+	IsDSImage    bool
+	Debug        bool
+
+	ReportLocation string
+	ClientConfig   *DockerClientConfig
+	CRTConnection  string
+
+	// EventLogPath, when non-empty, turns on the NDJSON event sink for the
+	// command's Output (see Output.SetEventLog).
+	EventLogPath    string
+	EventLogMaxSize int64
+	EventLogMaxAge  time.Duration
+
+	// SubscribeAddr, when non-empty, starts a JSON-RPC subscription
+	// listener for the command's Output (see subscribe.AttachRPC).
+	SubscribeAddr string
+
+	// Theme, when non-empty, is a built-in theme name or a path to a theme
+	// file (see theme.Resolve) from the --theme CLI flag, taking priority
+	// over MINT_THEME and the user's theme config file.
+	Theme string
+}
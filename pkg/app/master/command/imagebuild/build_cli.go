@@ -0,0 +1,106 @@
+package imagebuild
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+)
+
+// lineParser turns one line of a build tool's progress output into a
+// BuildEvent, or ok=false if the line isn't one worth surfacing.
+type lineParser func(line string) (BuildEvent, bool)
+
+// runBuildCLI runs cmd (already configured with its args), parsing its
+// combined stdout/stderr line by line with parse and forwarding each
+// recognized line to events as soon as it's printed, instead of buffering
+// the whole build's output until the process exits.
+func runBuildCLI(logger *log.Entry, xc *app.ExecutionContext, events chan<- BuildEvent, cmd *exec.Cmd, parse lineParser) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Run()
+		pw.Close()
+	}()
+
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if ev, ok := parse(line); ok {
+			events <- ev
+		} else {
+			logger.Trace(line)
+		}
+	}
+
+	if err := <-done; err != nil {
+		xc.FailOn(fmt.Errorf("%s: %w", strings.Join(cmd.Args, " "), err))
+	}
+}
+
+// splitKV splits a '--build-arg'/'--label' style "key=value" flag value.
+func splitKV(s string) (key string, value string, ok bool) {
+	idx := strings.Index(s, "=")
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	return s[:idx], s[idx+1:], true
+}
+
+// buildkitStepPattern matches BuildKit's '--progress=plain' step header
+// lines, e.g. '#5 [2/4] RUN go build ./...'.
+var buildkitStepPattern = regexp.MustCompile(`^#\d+ \[`)
+
+// parseBuildkitLine recognizes step/cache/export lines from a BuildKit
+// frontend's (buildx, depot) '--progress=plain' output. Depot's CLI wraps
+// BuildKit and shares this output shape.
+func parseBuildkitLine(line string) (BuildEvent, bool) {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case buildkitStepPattern.MatchString(trimmed):
+		return BuildEvent{Kind: BuildEventStepStart, Message: trimmed}, true
+	case strings.Contains(trimmed, "CACHED"):
+		return BuildEvent{Kind: BuildEventCacheHit, Message: trimmed}, true
+	case strings.Contains(trimmed, " DONE "):
+		return BuildEvent{Kind: BuildEventStepEnd, Message: trimmed}, true
+	case strings.Contains(trimmed, "writing image") || strings.Contains(trimmed, "exporting layers"):
+		return BuildEvent{Kind: BuildEventPushedBlob, Message: trimmed}, true
+	case strings.Contains(trimmed, "WARN"):
+		return BuildEvent{Kind: BuildEventWarning, Message: trimmed}, true
+	default:
+		return BuildEvent{}, false
+	}
+}
+
+// podmanStepPattern matches podman/buildah's classic 'STEP N/M: <instr>'
+// build output lines.
+var podmanStepPattern = regexp.MustCompile(`^STEP \d+/\d+:`)
+
+// parsePodmanLine recognizes step/cache/commit lines from 'podman build's
+// output.
+func parsePodmanLine(line string) (BuildEvent, bool) {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case podmanStepPattern.MatchString(trimmed):
+		return BuildEvent{Kind: BuildEventStepStart, Message: trimmed}, true
+	case strings.HasPrefix(trimmed, "--> Using cache"):
+		return BuildEvent{Kind: BuildEventCacheHit, Message: trimmed}, true
+	case strings.HasPrefix(trimmed, "-->"):
+		return BuildEvent{Kind: BuildEventLayer, Message: trimmed}, true
+	case strings.HasPrefix(trimmed, "COMMIT"):
+		return BuildEvent{Kind: BuildEventStepEnd, Message: trimmed}, true
+	default:
+		return BuildEvent{}, false
+	}
+}
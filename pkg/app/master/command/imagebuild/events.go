@@ -0,0 +1,77 @@
+package imagebuild
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+)
+
+// BuildEventKind identifies the kind of structured build event emitted by an
+// engine handler while a build is in progress.
+type BuildEventKind string
+
+const (
+	BuildEventStepStart  BuildEventKind = "step.start"
+	BuildEventStepEnd    BuildEventKind = "step.end"
+	BuildEventLayer      BuildEventKind = "layer"
+	BuildEventCacheHit   BuildEventKind = "cache.hit"
+	BuildEventPushedBlob BuildEventKind = "blob.pushed"
+	BuildEventWarning    BuildEventKind = "warning"
+)
+
+// BuildEvent is a single structured progress event produced by a build
+// engine handler (step start/end, layer digests, cache hits, pushed blobs,
+// warnings) instead of raw, buffered build output.
+type BuildEvent struct {
+	Kind    BuildEventKind    `json:"kind"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// newProgressWriter starts a goroutine that handles each BuildEvent received
+// on events according to the selected --progress mode ('json' sends the
+// event's JSON encoding to the 'cmd.output.stream' data channel; every other
+// mode prints it as a line and sends the event itself to that channel). It
+// returns a stop function that closes events and waits for the goroutine to
+// drain it, or a no-op when events is nil.
+func newProgressWriter(logger *log.Entry, xc *app.ExecutionContext, events chan BuildEvent, progress string) func() {
+	if events == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range events {
+			switch progress {
+			case ProgressJSON:
+				data, err := json.Marshal(ev)
+				if err != nil {
+					logger.WithError(err).Debug("imagebuild.events: marshal error")
+					continue
+				}
+
+				xc.Out.Data("cmd.output.stream", string(data))
+			default: //auto, plain and tty all render as a simple line for now
+				fmt.Println(renderBuildEvent(ev))
+				xc.Out.Data("cmd.output.stream", ev)
+			}
+		}
+	}()
+
+	return func() {
+		close(events)
+		<-done
+	}
+}
+
+func renderBuildEvent(ev BuildEvent) string {
+	if len(ev.Attrs) == 0 {
+		return fmt.Sprintf("build.event kind=%s message=%q", ev.Kind, ev.Message)
+	}
+
+	return fmt.Sprintf("build.event kind=%s message=%q attrs=%v", ev.Kind, ev.Message, ev.Attrs)
+}
@@ -46,7 +46,10 @@ const (
 	FlagLabelUsage = "image label to add"
 
 	FlagArchitecture      = "architecture"
-	FlagArchitectureUsage = "build architecture"
+	FlagArchitectureUsage = "build architecture (repeatable; when more than one value is given a multi-arch manifest list is assembled and pushed)"
+
+	FlagManifest      = "manifest"
+	FlagManifestUsage = "name of the OCI manifest list (fat manifest) to push the per-architecture images to when more than one --architecture is given; if it already exists in the registry, the new per-arch images are appended to it, replacing any entry for the same architecture"
 
 	FlagBase      = "base"
 	FlagBaseUsage = "base image to use (from selected runtime, docker by default, or pulled if not available)"
@@ -59,8 +62,56 @@ const (
 
 	FlagExePath      = "exe-path"
 	FlagExePathUsage = "local (linux) executable file that will be used as the entrypoint for the new image (added to the selected base image or scratch image if no base image is provided)"
+
+	FlagSource      = "source"
+	FlagSourceUsage = "source repository URL or local directory to build (for the 's2i' engine)"
+
+	FlagBuilderImage      = "builder-image"
+	FlagBuilderImageUsage = "builder image used to assemble the source into a runnable image (for the 's2i' engine)"
+
+	FlagRuntimeImage      = "runtime-image"
+	FlagRuntimeImageUsage = "runtime image recorded in the built image's 'io.openshift.s2i.build.runtime-image' label (for the 's2i' engine); the assembled artifacts are not copied into it"
+
+	FlagIncremental      = "incremental"
+	FlagIncrementalUsage = "reuse '/tmp/artifacts' from the previously tagged image to speed up the assemble step (for the 's2i' engine)"
+
+	FlagLocalRegistry      = "local-registry"
+	FlagLocalRegistryUsage = "start an ephemeral local registry for the duration of the command and push/load the built image there instead of requiring an external registry"
+
+	FlagProgress      = "progress"
+	FlagProgressUsage = "build progress output mode ('auto', 'plain', 'json', or 'tty')"
+
+	FlagPullPolicy      = "pull-policy"
+	FlagPullPolicyUsage = "pull policy for the base image ('always', 'never', or 'if-not-present')"
+
+	FlagBuilderPullPolicy      = "builder-pull-policy"
+	FlagBuilderPullPolicyUsage = "pull policy for the builder image, for engines that have one (defaults to --pull-policy)"
+)
+
+const (
+	ProgressAuto  = "auto"
+	ProgressPlain = "plain"
+	ProgressJSON  = "json"
+	ProgressTTY   = "tty"
+
+	DefaultProgress = ProgressAuto
 )
 
+var ProgressModes = map[string]struct{}{
+	ProgressAuto:  {},
+	ProgressPlain: {},
+	ProgressJSON:  {},
+	ProgressTTY:   {},
+}
+
+func IsProgressValue(name string) bool {
+	if _, ok := ProgressModes[name]; ok {
+		return true
+	}
+
+	return false
+}
+
 const (
 	DefaultImageName        = "mint-new-container-image:latest"
 	DefaultImageArchiveFile = "mint-new-container-image.tar"
@@ -81,12 +132,29 @@ const (
 	PodmanBuildEngineInfo   = "Native Podman/Buildah container build engine"
 	SimpleBuildEngine       = "simple"
 	SimpleBuildEngineInfo   = "Built-in container build engine for simple images that do not use 'RUN' instructions"
+	S2IBuildEngine          = "s2i"
+	S2IBuildEngineInfo      = "Source-to-Image (S2I) container build engine that assembles a source repository or directory into a runnable image using a builder image"
+
+	S2IAssembleScript = "/usr/libexec/s2i/assemble"
+	S2ISourcePath     = "/tmp/src"
+	S2IArtifactsPath  = "/tmp/artifacts"
+
+	S2ILabelBuilderImage = "io.openshift.s2i.build.image"
+	S2ILabelSource       = "io.openshift.s2i.build.source-location"
+	S2ILabelScriptsURL   = "io.openshift.s2i.scripts-url"
+	S2ILabelRuntimeImage = "io.openshift.s2i.build.runtime-image"
+	S2ILabelIncremental  = "io.openshift.s2i.build.incremental"
 
 	Amd64Arch = "amd64"
 	Arm64Arch = "arm64"
 
 	DefaultRuntimeLoad = NoneRuntimeLoad
 	DefaultEngineName  = DockerBuildEngine
+
+	PullPolicyAlways       = "always"
+	PullPolicyNever        = "never"
+	PullPolicyIfNotPresent = "if-not-present"
+	DefaultPullPolicy      = PullPolicyIfNotPresent
 )
 
 func GetDefaultBuildArch() string {
@@ -138,6 +206,20 @@ func IsArchValue(name string) bool {
 	return false
 }
 
+var PullPolicies = map[string]struct{}{
+	PullPolicyAlways:       {},
+	PullPolicyNever:        {},
+	PullPolicyIfNotPresent: {},
+}
+
+func IsPullPolicyValue(name string) bool {
+	if _, ok := PullPolicies[name]; ok {
+		return true
+	}
+
+	return false
+}
+
 var BuildEngines = map[string]BuildEngineProps{
 	DockerBuildEngine: {Info: DockerBuildEngineInfo},
 	BuildkitBuildEngine: {
@@ -154,6 +236,7 @@ var BuildEngines = map[string]BuildEngineProps{
 	},
 	PodmanBuildEngine: {Info: PodmanBuildEngineInfo},
 	SimpleBuildEngine: {Info: SimpleBuildEngineInfo},
+	S2IBuildEngine:    {Info: S2IBuildEngineInfo},
 }
 
 var Flags = map[string]cli.Flag{
@@ -222,12 +305,18 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagRuntimeLoadUsage,
 		EnvVars: []string{"DSLIM_IMAGEBUILD_RUNTIME_LOAD"},
 	},
-	FlagArchitecture: &cli.StringFlag{
+	FlagArchitecture: &cli.StringSliceFlag{
 		Name:    FlagArchitecture,
-		Value:   GetDefaultBuildArch(),
+		Value:   cli.NewStringSlice(GetDefaultBuildArch()),
 		Usage:   FlagArchitectureUsage,
 		EnvVars: []string{"DSLIM_IMAGEBUILD_ARCH"},
 	},
+	FlagManifest: &cli.StringFlag{
+		Name:    FlagManifest,
+		Value:   "",
+		Usage:   FlagManifestUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_MANIFEST"},
+	},
 	FlagBase: &cli.StringFlag{
 		Name:    FlagBase,
 		Value:   "",
@@ -257,6 +346,54 @@ var Flags = map[string]cli.Flag{
 		Usage:   FlagRegistryPushUsage,
 		EnvVars: []string{"DSLIM_IMAGEBUILD_REGISTRY_PUSH"},
 	},
+	FlagSource: &cli.StringFlag{
+		Name:    FlagSource,
+		Value:   "",
+		Usage:   FlagSourceUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_SOURCE"},
+	},
+	FlagBuilderImage: &cli.StringFlag{
+		Name:    FlagBuilderImage,
+		Value:   "",
+		Usage:   FlagBuilderImageUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_BUILDER_IMAGE"},
+	},
+	FlagRuntimeImage: &cli.StringFlag{
+		Name:    FlagRuntimeImage,
+		Value:   "",
+		Usage:   FlagRuntimeImageUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_RUNTIME_IMAGE"},
+	},
+	FlagIncremental: &cli.BoolFlag{
+		Name:    FlagIncremental,
+		Value:   false,
+		Usage:   FlagIncrementalUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_INCREMENTAL"},
+	},
+	FlagLocalRegistry: &cli.BoolFlag{
+		Name:    FlagLocalRegistry,
+		Value:   false,
+		Usage:   FlagLocalRegistryUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_LOCAL_REGISTRY"},
+	},
+	FlagProgress: &cli.StringFlag{
+		Name:    FlagProgress,
+		Value:   DefaultProgress,
+		Usage:   FlagProgressUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_PROGRESS"},
+	},
+	FlagPullPolicy: &cli.StringFlag{
+		Name:    FlagPullPolicy,
+		Value:   DefaultPullPolicy,
+		Usage:   FlagPullPolicyUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_PULL_POLICY"},
+	},
+	FlagBuilderPullPolicy: &cli.StringFlag{
+		Name:    FlagBuilderPullPolicy,
+		Value:   "",
+		Usage:   FlagBuilderPullPolicyUsage,
+		EnvVars: []string{"DSLIM_IMAGEBUILD_BUILDER_PULL_POLICY"},
+	},
 }
 
 func cflag(name string) cli.Flag {
@@ -0,0 +1,24 @@
+package imagebuild
+
+import "testing"
+
+func TestIsPullPolicyValue(t *testing.T) {
+	testCases := []struct {
+		name string
+		want bool
+	}{
+		{PullPolicyAlways, true},
+		{PullPolicyNever, true},
+		{PullPolicyIfNotPresent, true},
+		{"", false},
+		{"sometimes", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsPullPolicyValue(tc.name); got != tc.want {
+				t.Errorf("IsPullPolicyValue(%q) = %v, want %v", tc.name, got, tc.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package imagebuild
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"os"
+	"os/exec"
 
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -12,6 +15,8 @@ import (
 	"github.com/mintoolkit/mint/pkg/app"
 	"github.com/mintoolkit/mint/pkg/app/master/command"
 	"github.com/mintoolkit/mint/pkg/app/master/version"
+	"github.com/mintoolkit/mint/pkg/app/subscribe"
+	"github.com/mintoolkit/mint/pkg/app/theme"
 	cmd "github.com/mintoolkit/mint/pkg/command"
 
 	"github.com/mintoolkit/mint/pkg/crt"
@@ -38,6 +43,10 @@ func OnCommand(
 	const cmdName = Name
 	logger := log.WithFields(log.Fields{"app": appName, "cmd": cmdName})
 
+	if gparams.Theme != "" {
+		xc.Out.SetTheme(theme.Resolve(gparams.Theme))
+	}
+
 	viChan := version.CheckAsync(gparams.CheckVersion, gparams.InContainer, gparams.IsDSImage)
 
 	cmdReport := report.NewImageBuildCommand(gparams.ReportLocation, gparams.InContainer)
@@ -50,6 +59,40 @@ func OnCommand(
 			"cparams": jsonutil.ToString(cparams),
 		})
 
+	if gparams.EventLogPath != "" {
+		elErr := xc.Out.SetEventLog(gparams.EventLogPath, gparams.EventLogMaxSize, gparams.EventLogMaxAge)
+		xc.FailOn(elErr)
+	}
+
+	if gparams.SubscribeAddr != "" {
+		listener, lErr := net.Listen("tcp", gparams.SubscribeAddr)
+		xc.FailOn(lErr)
+		xc.AddCleanupHandler(func() { listener.Close() })
+
+		go func() {
+			if rpcErr := subscribe.AttachRPC(xc.Out, listener, logger); rpcErr != nil {
+				logger.WithError(rpcErr).Debug("subscribe.attach.rpc: listener closed")
+			}
+		}()
+
+		xc.Out.Info("subscribe.listening", ovars{"address": gparams.SubscribeAddr})
+	}
+
+	if cparams.PullPolicy != "" && !IsPullPolicyValue(cparams.PullPolicy) {
+		xc.Out.Error("pull.policy", fmt.Sprintf("unknown --pull-policy value '%s'", cparams.PullPolicy))
+		xc.Exit(-1)
+	}
+
+	if cparams.BuilderPullPolicy != "" && !IsPullPolicyValue(cparams.BuilderPullPolicy) {
+		xc.Out.Error("builder.pull.policy", fmt.Sprintf("unknown --builder-pull-policy value '%s'", cparams.BuilderPullPolicy))
+		xc.Exit(-1)
+	}
+
+	if len(cparams.Architectures) > 1 && len(cparams.LoadRuntimes) > 0 {
+		xc.Out.Error("runtime.load", "--runtime-load isn't supported together with multiple --architecture values (there's no single-arch image archive to load); push a multi-arch manifest with --registry-push instead")
+		xc.Exit(-1)
+	}
+
 	var err error
 	var dclient *docker.Client
 	var pclient context.Context
@@ -100,52 +143,126 @@ func OnCommand(
 		}
 	}
 
-	switch cparams.Engine {
-	case DockerBuildEngine:
-		initDockerClient()
+	archImages := map[string]string{} // arch -> image archive file for that arch's build
 
-		if gparams.Debug {
-			version.Print(xc, cmdName, logger, dclient, false, gparams.InContainer, gparams.IsDSImage)
-		}
+	buildOneArch := func(arch, imageArchiveFile string) {
+		// The docker, depot, buildkit and podman engine handlers stream
+		// granular BuildEvents (step/layer/cache.hit/blob.pushed) into this
+		// channel as the build runs; S2I manages its own events internally,
+		// and the simple engine emits none.
+		events := make(chan BuildEvent, 16)
+		stopProgress := newProgressWriter(logger, xc, events, cparams.Progress)
 
-		HandleDockerEngine(logger, xc, gparams, cparams, dclient)
-	case DepotBuildEngine:
-		if gparams.Debug {
-			version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
-		}
+		switch cparams.Engine {
+		case DockerBuildEngine:
+			initDockerClient()
+
+			if gparams.Debug {
+				version.Print(xc, cmdName, logger, dclient, false, gparams.InContainer, gparams.IsDSImage)
+			}
+
+			xc.FailOn(ResolveBaseImage(logger, dclient, cparams))
+			HandleDockerEngine(logger, xc, gparams, cparams, dclient, events)
+		case DepotBuildEngine:
+			if gparams.Debug {
+				version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+			}
+
+			HandleDepotEngine(logger, xc, gparams, cparams, events)
+		case BuildkitBuildEngine:
+			if gparams.Debug {
+				version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+			}
+
+			HandleBuildkitEngine(logger, xc, gparams, cparams, events)
+		case SimpleBuildEngine:
+			initDockerClient() //tmp
+			if gparams.Debug {
+				version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+			}
+
+			xc.FailOn(ResolveBaseImage(logger, dclient, cparams))
+			HandleSimpleEngine(logger, xc, gparams, cparams, dclient)
+		case PodmanBuildEngine:
+			initPodmanClient()
+
+			if gparams.Debug {
+				version.Print(xc, Name, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+			}
 
-		HandleDepotEngine(logger, xc, gparams, cparams)
-	case BuildkitBuildEngine:
-		if gparams.Debug {
-			version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+			HandlePodmanEngine(logger, xc, gparams, cparams, pclient, events)
+		case S2IBuildEngine:
+			initDockerClient()
+
+			if gparams.Debug {
+				version.Print(xc, cmdName, logger, dclient, false, gparams.InContainer, gparams.IsDSImage)
+			}
+
+			HandleS2IEngine(logger, xc, gparams, cparams, dclient)
+		default:
+			xc.Out.Error("engine", "unsupported engine")
+			xc.Out.State("exited",
+				ovars{
+					"exit.code": -1,
+					"version":   v.Current(),
+					"location":  fsutil.ExeDir(),
+					"runtime":   cparams.Engine,
+				})
+			xc.Exit(-1)
 		}
 
-		HandleBuildkitEngine(logger, xc, gparams, cparams)
-	case SimpleBuildEngine:
-		initDockerClient() //tmp
-		if gparams.Debug {
-			version.Print(xc, cmdName, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+		stopProgress()
+		archImages[arch] = imageArchiveFile
+	}
+
+	if len(cparams.Architectures) <= 1 {
+		buildOneArch(cparams.Architecture, cparams.ImageArchiveFile)
+	} else {
+		baseArchiveFile := cparams.ImageArchiveFile
+		for _, arch := range cparams.Architectures {
+			xc.Out.Info("imagebuild.arch", ovars{"architecture": arch})
+
+			cparams.Architecture = arch
+			cparams.ImageArchiveFile = fmt.Sprintf("%s.%s", baseArchiveFile, arch)
+			buildOneArch(arch, cparams.ImageArchiveFile)
 		}
 
-		HandleSimpleEngine(logger, xc, gparams, cparams, dclient)
-	case PodmanBuildEngine:
-		initPodmanClient()
+		cparams.ImageArchiveFile = baseArchiveFile
+	}
+
+	nameOpts := []name.Option{
+		name.WeakValidation,
+		name.Insecure,
+	}
 
-		if gparams.Debug {
-			version.Print(xc, Name, logger, nil, false, gparams.InContainer, gparams.IsDSImage)
+	// Starting the ephemeral registry here (instead of only when
+	// RegistryPush is set) and pushing the just-built image into it gives
+	// runtime-load a single canonical registry URL that Docker and Podman
+	// can both pull from the same way, instead of the loader having to
+	// match whichever engine produced the local archive.
+	var localRegistry *registry.EphemeralRegistry
+	if cparams.LocalRegistry && (cparams.RegistryPush || len(cparams.LoadRuntimes) > 0) {
+		if dclient == nil {
+			initDockerClient()
 		}
 
-		HandlePodmanEngine(logger, xc, gparams, cparams, pclient)
-	default:
-		xc.Out.Error("engine", "unsupported engine")
-		xc.Out.State("exited",
+		var lrErr error
+		localRegistry, lrErr = registry.StartEphemeralRegistry(logger, dclient)
+		xc.FailOn(lrErr)
+		xc.AddCleanupHandler(func() { localRegistry.Stop() })
+
+		cparams.ImageName = localRegistry.QualifyImageName(cparams.ImageName)
+		xc.Out.Info("local.registry",
 			ovars{
-				"exit.code": -1,
-				"version":   v.Current(),
-				"location":  fsutil.ExeDir(),
-				"runtime":   cparams.Engine,
+				"address": localRegistry.Addr(),
+				"image":   cparams.ImageName,
 			})
-		xc.Exit(-1)
+
+		if len(cparams.LoadRuntimes) > 0 {
+			err = registry.PushImageFromTar(logger, cparams.ImageArchiveFile, cparams.ImageName, nameOpts,
+				[]remote.Option{remote.WithContext(context.Background())})
+			xc.FailOn(err)
+		}
 	}
 
 	crtLoaderClients := map[string]crt.ImageLoaderAPIClient{}
@@ -173,12 +290,16 @@ func OnCommand(
 				"image.archive.file": cparams.ImageArchiveFile,
 			})
 
-			if !((cparams.Engine == PodmanBuildEngine && rt == PodmanRuntimeLoad) ||
-				(cparams.Engine == DockerBuildEngine && rt == DockerRuntimeLoad)) {
+			switch {
+			case (cparams.Engine == PodmanBuildEngine && rt == PodmanRuntimeLoad) ||
+				(cparams.Engine == DockerBuildEngine && rt == DockerRuntimeLoad):
+				xc.Out.Info("same.image.engine.runtime")
+			case localRegistry != nil:
+				xc.Out.Info("runtime.load.image.registry.pull", ovars{"runtime": rt, "image": cparams.ImageName})
+				xc.FailOn(pullThroughRegistry(pclient, dclient, rt, cparams.ImageName))
+			default:
 				err = client.LoadImage(cparams.ImageArchiveFile, os.Stdout)
 				xc.FailOn(err)
-			} else {
-				xc.Out.Info("same.image.engine.runtime")
 			}
 		}
 	} else {
@@ -197,12 +318,18 @@ func OnCommand(
 
 		xc.FailOn(err)
 
-		nameOpts := []name.Option{
-			name.WeakValidation,
-			name.Insecure,
+		if len(cparams.Architectures) <= 1 {
+			err = registry.PushImageFromTar(logger, cparams.ImageArchiveFile, cparams.ImageName, nameOpts, remoteOpts)
+			xc.FailOn(err)
+		} else {
+			manifestName := cparams.Manifest
+			if manifestName == "" {
+				manifestName = cparams.ImageName
+			}
+
+			err = registry.PushManifestList(logger, archImages, manifestName, cparams.Manifest != "", nameOpts, remoteOpts)
+			xc.FailOn(err)
 		}
-		err = registry.PushImageFromTar(logger, cparams.ImageArchiveFile, cparams.ImageName, nameOpts, remoteOpts)
-		xc.FailOn(err)
 	}
 
 	xc.Out.State(cmd.StateCompleted)
@@ -220,3 +347,25 @@ func OnCommand(
 			})
 	}
 }
+
+// pullThroughRegistry pulls image into the runtime identified by rt using
+// that runtime's own native pull path, instead of loading it from the local
+// build archive. Used once a local registry holds the just-built image, so
+// Docker and Podman both land the same bits the same way regardless of which
+// engine built them.
+func pullThroughRegistry(pclient context.Context, dclient *docker.Client, rt, image string) error {
+	switch rt {
+	case DockerRuntimeLoad:
+		return dclient.PullImage(docker.PullImageOptions{
+			Repository:   image,
+			OutputStream: os.Stdout,
+		}, docker.AuthConfiguration{})
+	case PodmanRuntimeLoad:
+		cmd := exec.CommandContext(pclient, "podman", "pull", image)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stdout
+		return cmd.Run()
+	default:
+		return fmt.Errorf("pullThroughRegistry: unsupported runtime '%s'", rt)
+	}
+}
@@ -0,0 +1,57 @@
+package imagebuild
+
+import (
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+	"github.com/mintoolkit/mint/pkg/app/master/command"
+)
+
+// HandleBuildkitEngine implements the 'buildkit' image build engine by
+// driving 'docker buildx build' against the configured builder endpoint,
+// streaming its '--progress=plain' output into granular BuildEvents as the
+// build runs instead of buffering it until the build finishes.
+func HandleBuildkitEngine(
+	logger *log.Entry,
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	cparams *CommandParams,
+	events chan<- BuildEvent) {
+	logger = logger.WithField("engine", BuildkitBuildEngine)
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	args := []string{
+		"buildx", "build",
+		"--file", cparams.Dockerfile,
+		"--tag", cparams.ImageName,
+		"--progress", "plain",
+		"--output", "type=docker,dest=" + cparams.ImageArchiveFile,
+	}
+
+	if cparams.EngineEndpoint != "" {
+		args = append(args, "--builder", cparams.EngineEndpoint)
+	}
+
+	for _, ba := range cparams.BuildArgs {
+		if ba != "" {
+			args = append(args, "--build-arg", ba)
+		}
+	}
+
+	for _, l := range cparams.Labels {
+		if l != "" {
+			args = append(args, "--label", l)
+		}
+	}
+
+	if cparams.Architecture != "" {
+		args = append(args, "--platform", "linux/"+cparams.Architecture)
+	}
+
+	args = append(args, cparams.ContextDir)
+
+	runBuildCLI(logger, xc, events, exec.Command("docker", args...), parseBuildkitLine)
+}
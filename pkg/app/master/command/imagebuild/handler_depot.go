@@ -0,0 +1,63 @@
+package imagebuild
+
+import (
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+	"github.com/mintoolkit/mint/pkg/app/master/command"
+)
+
+// HandleDepotEngine implements the 'depot' image build engine by driving the
+// 'depot build' CLI (a BuildKit frontend for Depot.dev's remote builders),
+// streaming its '--progress=plain' output into granular BuildEvents as the
+// build runs instead of buffering it until the build finishes.
+func HandleDepotEngine(
+	logger *log.Entry,
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	cparams *CommandParams,
+	events chan<- BuildEvent) {
+	logger = logger.WithField("engine", DepotBuildEngine)
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	args := []string{
+		"build",
+		"--file", cparams.Dockerfile,
+		"--tag", cparams.ImageName,
+		"--progress", "plain",
+		"--output", "type=docker,dest=" + cparams.ImageArchiveFile,
+	}
+
+	if cparams.EngineToken != "" {
+		args = append(args, "--token", cparams.EngineToken)
+	}
+
+	if cparams.EngineNamespace != "" {
+		args = append(args, "--project", cparams.EngineNamespace)
+	}
+
+	for _, ba := range cparams.BuildArgs {
+		if ba != "" {
+			args = append(args, "--build-arg", ba)
+		}
+	}
+
+	for _, l := range cparams.Labels {
+		if l != "" {
+			args = append(args, "--label", l)
+		}
+	}
+
+	if cparams.Architecture != "" {
+		args = append(args, "--platform", "linux/"+cparams.Architecture)
+	}
+
+	args = append(args, cparams.ContextDir)
+
+	// depot's CLI wraps BuildKit and emits the same '--progress=plain'
+	// output shape as 'docker buildx build', so it shares parseBuildkitLine.
+	runBuildCLI(logger, xc, events, exec.Command("depot", args...), parseBuildkitLine)
+}
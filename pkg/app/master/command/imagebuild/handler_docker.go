@@ -0,0 +1,145 @@
+package imagebuild
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+	"github.com/mintoolkit/mint/pkg/app/master/command"
+)
+
+// HandleDockerEngine implements the 'docker' image build engine: a native
+// Dockerfile build through the Docker API, exported to --image-archive-file
+// for the runtime-load/registry-push steps that follow.
+func HandleDockerEngine(
+	logger *log.Entry,
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	cparams *CommandParams,
+	dclient *docker.Client,
+	events chan<- BuildEvent) {
+	logger = logger.WithField("engine", DockerBuildEngine)
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	buildArgs := make([]docker.BuildArg, 0, len(cparams.BuildArgs))
+	for _, ba := range cparams.BuildArgs {
+		if k, v, ok := splitKV(ba); ok {
+			buildArgs = append(buildArgs, docker.BuildArg{Name: k, Value: v})
+		}
+	}
+
+	labels := map[string]string{}
+	for _, l := range cparams.Labels {
+		if k, v, ok := splitKV(l); ok {
+			labels[k] = v
+		}
+	}
+
+	var platform string
+	if cparams.Architecture != "" {
+		platform = "linux/" + cparams.Architecture
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if ev, ok := parseDockerBuildLine(scanner.Bytes()); ok {
+				events <- ev
+			}
+		}
+	}()
+
+	err := dclient.BuildImage(docker.BuildImageOptions{
+		Name:           cparams.ImageName,
+		Dockerfile:     cparams.Dockerfile,
+		ContextDir:     cparams.ContextDir,
+		BuildArgs:      buildArgs,
+		Labels:         labels,
+		Platform:       platform,
+		RmTmpContainer: true,
+		OutputStream:   pw,
+		RawJSONStream:  true,
+	})
+	pw.Close()
+	<-done
+	xc.FailOn(err)
+
+	image, iErr := dclient.InspectImage(cparams.ImageName)
+	if iErr == nil {
+		events <- BuildEvent{Kind: BuildEventLayer, Message: "built image", Attrs: map[string]string{"id": image.ID}}
+	}
+
+	xc.FailOn(exportImageArchive(dclient, cparams.ImageName, cparams.ImageArchiveFile))
+	events <- BuildEvent{Kind: BuildEventStepEnd, Message: "image.archive.saved", Attrs: map[string]string{"file": cparams.ImageArchiveFile}}
+}
+
+// exportImageArchive saves imageName to archiveFile as a docker-loadable
+// tar, the format the runtime-load and registry-push steps downstream both
+// expect.
+func exportImageArchive(dclient *docker.Client, imageName, archiveFile string) error {
+	f, err := os.Create(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return dclient.ExportImage(docker.ExportImageOptions{
+		Name:         imageName,
+		OutputStream: f,
+	})
+}
+
+// dockerBuildLine is the shape of one line of the Docker API's JSON build
+// output stream (BuildImageOptions.RawJSONStream).
+type dockerBuildLine struct {
+	Stream string `json:"stream"`
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+// parseDockerBuildLine turns one line of the Docker API's JSON build output
+// into a BuildEvent (step/cache-hit lines from "stream", pull/push progress
+// from "status"/"id").
+func parseDockerBuildLine(data []byte) (BuildEvent, bool) {
+	var msg dockerBuildLine
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return BuildEvent{}, false
+	}
+
+	switch {
+	case msg.Stream != "":
+		trimmed := strings.TrimSpace(msg.Stream)
+		if trimmed == "" {
+			return BuildEvent{}, false
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "Step "):
+			return BuildEvent{Kind: BuildEventStepStart, Message: trimmed}, true
+		case strings.Contains(trimmed, "Using cache"):
+			return BuildEvent{Kind: BuildEventCacheHit, Message: trimmed}, true
+		default:
+			return BuildEvent{Kind: BuildEventLayer, Message: trimmed}, true
+		}
+	case msg.Status != "":
+		attrs := map[string]string{}
+		if msg.ID != "" {
+			attrs["id"] = msg.ID
+		}
+
+		return BuildEvent{Kind: BuildEventPushedBlob, Message: msg.Status, Attrs: attrs}, true
+	default:
+		return BuildEvent{}, false
+	}
+}
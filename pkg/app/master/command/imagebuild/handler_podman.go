@@ -0,0 +1,59 @@
+package imagebuild
+
+import (
+	"context"
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+	"github.com/mintoolkit/mint/pkg/app/master/command"
+)
+
+// HandlePodmanEngine implements the 'podman' image build engine by driving
+// the local 'podman build' CLI (pclient only bounds the lifetime of the
+// podman processes it runs), streaming its step-by-step output into granular
+// BuildEvents as the build runs instead of buffering it until the build
+// finishes.
+func HandlePodmanEngine(
+	logger *log.Entry,
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	cparams *CommandParams,
+	pclient context.Context,
+	events chan<- BuildEvent) {
+	logger = logger.WithField("engine", PodmanBuildEngine)
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	args := []string{
+		"build",
+		"--file", cparams.Dockerfile,
+		"--tag", cparams.ImageName,
+	}
+
+	for _, ba := range cparams.BuildArgs {
+		if ba != "" {
+			args = append(args, "--build-arg", ba)
+		}
+	}
+
+	for _, l := range cparams.Labels {
+		if l != "" {
+			args = append(args, "--label", l)
+		}
+	}
+
+	if cparams.Architecture != "" {
+		args = append(args, "--platform", "linux/"+cparams.Architecture)
+	}
+
+	args = append(args, cparams.ContextDir)
+
+	runBuildCLI(logger, xc, events, exec.CommandContext(pclient, "podman", args...), parsePodmanLine)
+
+	events <- BuildEvent{Kind: BuildEventStepStart, Message: "image.archive.save"}
+	saveCmd := exec.CommandContext(pclient, "podman", "save", "-o", cparams.ImageArchiveFile, cparams.ImageName)
+	xc.FailOn(saveCmd.Run())
+	events <- BuildEvent{Kind: BuildEventStepEnd, Message: "image.archive.save", Attrs: map[string]string{"file": cparams.ImageArchiveFile}}
+}
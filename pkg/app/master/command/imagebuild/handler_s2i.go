@@ -0,0 +1,322 @@
+package imagebuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+	"github.com/mintoolkit/mint/pkg/app/master/command"
+)
+
+// S2IBuildConfig captures the inputs needed to run a Source-to-Image build.
+// It can be built from CLI flags or reconstructed from the 'io.openshift.s2i.*'
+// labels of a previously built image (see GenerateConfigFromLabels).
+type S2IBuildConfig struct {
+	Source       string
+	BuilderImage string
+	RuntimeImage string
+	Incremental  bool
+	Tag          string
+}
+
+// HandleS2IEngine implements the 's2i' image build engine.
+// Instead of a Dockerfile, it injects a source repository/directory into the
+// builder image and runs the builder's assemble script to produce a new image.
+func HandleS2IEngine(
+	logger *log.Entry,
+	xc *app.ExecutionContext,
+	gparams *command.GenericParams,
+	cparams *CommandParams,
+	dclient *docker.Client) {
+	logger = logger.WithField("engine", S2IBuildEngine)
+	logger.Trace("call")
+	defer logger.Trace("exit")
+
+	events := make(chan BuildEvent, 16)
+	stopProgress := newProgressWriter(logger, xc, events, cparams.Progress)
+	defer stopProgress()
+
+	xc.Out.State("s2i.build.started")
+
+	bcfg := S2IBuildConfig{
+		Source:       cparams.Source,
+		BuilderImage: cparams.BuilderImage,
+		RuntimeImage: cparams.RuntimeImage,
+		Incremental:  cparams.Incremental,
+		Tag:          cparams.ImageName,
+	}
+
+	if bcfg.Source == "" || bcfg.BuilderImage == "" {
+		if prior, perr := dclient.InspectImage(bcfg.Tag); perr == nil && prior != nil && prior.Config != nil {
+			fromLabels := GenerateConfigFromLabels(bcfg.Tag, prior.Config.Labels)
+			if bcfg.Source == "" {
+				bcfg.Source = fromLabels.Source
+			}
+			if bcfg.BuilderImage == "" {
+				bcfg.BuilderImage = fromLabels.BuilderImage
+			}
+			if bcfg.RuntimeImage == "" {
+				bcfg.RuntimeImage = fromLabels.RuntimeImage
+			}
+			if !cparams.Incremental {
+				bcfg.Incremental = fromLabels.Incremental
+			}
+		}
+	}
+
+	if bcfg.Source == "" {
+		xc.Out.Error("s2i.build", "missing source (use --source, or rebuild an image that carries the io.openshift.s2i.* labels)")
+		xc.Exit(-1)
+	}
+
+	if bcfg.BuilderImage == "" {
+		xc.Out.Error("s2i.build", "missing builder image (use --builder-image, or rebuild an image that carries the io.openshift.s2i.* labels)")
+		xc.Exit(-1)
+	}
+
+	builderPullPolicy := cparams.BuilderPullPolicy
+	if builderPullPolicy == "" {
+		builderPullPolicy = cparams.PullPolicy
+	}
+	if builderPullPolicy == "" {
+		builderPullPolicy = DefaultPullPolicy
+	}
+
+	events <- BuildEvent{Kind: BuildEventStepStart, Message: "pull builder image", Attrs: map[string]string{"image": bcfg.BuilderImage}}
+	err := pullImage(logger, dclient, bcfg.BuilderImage, builderPullPolicy, cparams.Architecture)
+	xc.FailOn(err)
+	events <- BuildEvent{Kind: BuildEventStepEnd, Message: "pull builder image"}
+
+	sourceTar, err := tarSource(bcfg.Source)
+	xc.FailOn(err)
+
+	containerName := fmt.Sprintf("mint-s2i-%d", os.Getpid())
+	container, err := dclient.CreateContainer(docker.CreateContainerOptions{
+		Name: containerName,
+		Config: &docker.Config{
+			Image:      bcfg.BuilderImage,
+			Entrypoint: []string{"/bin/sh", "-c"},
+			Cmd:        []string{fmt.Sprintf("mkdir -p %s && %s", S2ISourcePath, S2IAssembleScript)},
+		},
+	})
+	xc.FailOn(err)
+
+	defer func() {
+		removeErr := dclient.RemoveContainer(docker.RemoveContainerOptions{
+			ID:    container.ID,
+			Force: true,
+		})
+		xc.WarnOn(removeErr)
+	}()
+
+	err = dclient.UploadToContainer(container.ID, docker.UploadToContainerOptions{
+		InputStream: sourceTar,
+		Path:        S2ISourcePath,
+		Context:     context.Background(),
+	})
+	xc.FailOn(err)
+
+	if bcfg.Incremental {
+		if artifacts, aerr := extractArtifacts(logger, dclient, bcfg.Tag); aerr == nil && artifacts != nil {
+			uerr := dclient.UploadToContainer(container.ID, docker.UploadToContainerOptions{
+				InputStream: artifacts,
+				Path:        S2IArtifactsPath,
+				Context:     context.Background(),
+			})
+			xc.WarnOn(uerr)
+		} else {
+			xc.Out.Info("s2i.build.incremental", app.OutVars{"message": "no previous image to source artifacts from"})
+		}
+	}
+
+	events <- BuildEvent{Kind: BuildEventStepStart, Message: "assemble"}
+	err = dclient.StartContainer(container.ID, nil)
+	xc.FailOn(err)
+
+	statusCode, err := dclient.WaitContainer(container.ID)
+	xc.FailOn(err)
+	if statusCode != 0 {
+		xc.Out.Error("s2i.build", fmt.Sprintf("assemble script exited with status %d", statusCode))
+		xc.Exit(statusCode)
+	}
+	events <- BuildEvent{Kind: BuildEventStepEnd, Message: "assemble"}
+
+	commitOpts := docker.CommitContainerOptions{
+		Container:  container.ID,
+		Repository: bcfg.Tag,
+		Run: &docker.Config{
+			Labels: map[string]string{
+				S2ILabelSource:       bcfg.Source,
+				S2ILabelBuilderImage: bcfg.BuilderImage,
+				S2ILabelRuntimeImage: bcfg.RuntimeImage,
+				S2ILabelIncremental:  fmt.Sprintf("%t", bcfg.Incremental),
+			},
+		},
+	}
+	image, err := dclient.CommitContainer(commitOpts)
+	xc.FailOn(err)
+
+	events <- BuildEvent{Kind: BuildEventLayer, Message: "committed image", Attrs: map[string]string{"id": image.ID}}
+
+	xc.Out.Info("s2i.build.image",
+		app.OutVars{
+			"id":   image.ID,
+			"name": bcfg.Tag,
+		})
+
+	xc.Out.State("s2i.build.completed")
+}
+
+// GenerateConfigFromLabels reconstructs an S2IBuildConfig for tag from the
+// 'io.openshift.s2i.*' labels HandleS2IEngine stamps onto every image it
+// assembles, allowing a rebuild without having to restate
+// --source/--builder-image/--runtime-image/--incremental on the command line.
+func GenerateConfigFromLabels(tag string, labels map[string]string) S2IBuildConfig {
+	return S2IBuildConfig{
+		Source:       labels[S2ILabelSource],
+		BuilderImage: labels[S2ILabelBuilderImage],
+		RuntimeImage: labels[S2ILabelRuntimeImage],
+		Incremental:  labels[S2ILabelIncremental] == "true",
+		Tag:          tag,
+	}
+}
+
+// gitSourcePattern matches the source repository URL forms FlagSourceUsage
+// advertises ('git clone'-able URLs and the git@host:path scp-like form);
+// anything else is treated as a local directory.
+var gitSourcePattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://|git@)`)
+
+func tarSource(source string) (io.Reader, error) {
+	if gitSourcePattern.MatchString(source) {
+		return tarGitSource(source)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("s2i: local source directory '%s' not found: %w", source, err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("s2i: source '%s' is not a directory", source)
+	}
+
+	return tarDir(source)
+}
+
+// tarGitSource clones a source repository URL into a temporary directory and
+// tars its working tree (the '.git' metadata isn't needed by the assemble
+// script, so it's left out of the build context).
+func tarGitSource(repoURL string) (io.Reader, error) {
+	cloneDir, err := os.MkdirTemp("", "mint-s2i-src-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	cmd := exec.Command("git", "clone", "--depth", "1", repoURL, cloneDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("s2i: git clone of '%s' failed: %w: %s", repoURL, err, out)
+	}
+
+	if err := os.RemoveAll(filepath.Join(cloneDir, ".git")); err != nil {
+		return nil, err
+	}
+
+	return tarDir(cloneDir)
+}
+
+func tarDir(source string) (io.Reader, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	defer tw.Close()
+
+	err := filepath.Walk(source, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		if relPath == "." {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return nil
+		}
+
+		data, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer data.Close()
+
+		_, err = io.Copy(tw, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
+
+func extractArtifacts(logger *log.Entry, dclient *docker.Client, tag string) (io.Reader, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	if _, err := dclient.InspectImage(tag); err != nil {
+		return nil, err
+	}
+
+	containerName := fmt.Sprintf("mint-s2i-artifacts-%d", os.Getpid())
+	container, err := dclient.CreateContainer(docker.CreateContainerOptions{
+		Name: containerName,
+		Config: &docker.Config{
+			Image: tag,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = dclient.RemoveContainer(docker.RemoveContainerOptions{ID: container.ID, Force: true})
+	}()
+
+	var buf bytes.Buffer
+	err = dclient.DownloadFromContainer(container.ID, docker.DownloadFromContainerOptions{
+		OutputStream: &buf,
+		Path:         S2IArtifactsPath,
+	})
+	if err != nil {
+		logger.WithError(err).Debug("no artifacts to extract")
+		return nil, err
+	}
+
+	return &buf, nil
+}
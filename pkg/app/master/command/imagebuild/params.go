@@ -0,0 +1,111 @@
+package imagebuild
+
+import "github.com/urfave/cli/v2"
+
+// Name is the mint sub-command name for this package.
+const Name = "imagebuild"
+
+// Usage is the one-line help text shown for the command in 'mint --help'.
+const Usage = "Build a new container image"
+
+// CommandParams holds the resolved --flag values for the 'imagebuild' command,
+// populated from the CLI context by NewCommandParams.
+type CommandParams struct {
+	Engine          string
+	EngineEndpoint  string
+	EngineToken     string
+	EngineNamespace string
+
+	ImageName        string
+	ImageArchiveFile string
+	Dockerfile       string
+	ContextDir       string
+	BuildArgs        []string
+	Labels           []string
+
+	Architecture  string
+	Architectures []string
+	Manifest      string
+
+	BaseImage     string
+	BaseImageTar  string
+	BaseWithCerts bool
+	ExePath       string
+
+	RegistryPush bool
+	// UseDockerCreds, CredsAccount and CredsSecret authenticate the
+	// --registry-push/--manifest push against the target registry; they
+	// come from the Docker config / credential-helper flags shared with
+	// the 'registry push' command rather than a flag of their own here.
+	UseDockerCreds bool
+	CredsAccount   string
+	CredsSecret    string
+
+	Source       string
+	BuilderImage string
+	RuntimeImage string
+	Incremental  bool
+
+	LocalRegistry bool
+
+	Progress string
+
+	PullPolicy        string
+	BuilderPullPolicy string
+
+	LoadRuntimes []string
+}
+
+// NewCommandParams reads the 'imagebuild' command's flags off ctx into a
+// CommandParams.
+func NewCommandParams(ctx *cli.Context) *CommandParams {
+	return &CommandParams{
+		Engine:          ctx.String(FlagEngine),
+		EngineEndpoint:  ctx.String(FlagEngineEndpoint),
+		EngineToken:     ctx.String(FlagEngineToken),
+		EngineNamespace: ctx.String(FlagEngineNamespace),
+
+		ImageName:        ctx.String(FlagImageName),
+		ImageArchiveFile: ctx.String(FlagImageArchiveFile),
+		Dockerfile:       ctx.String(FlagDockerfile),
+		ContextDir:       ctx.String(FlagContextDir),
+		BuildArgs:        ctx.StringSlice(FlagBuildArg),
+		Labels:           ctx.StringSlice(FlagLabel),
+
+		Architecture:  firstArch(ctx.StringSlice(FlagArchitecture)),
+		Architectures: ctx.StringSlice(FlagArchitecture),
+		Manifest:      ctx.String(FlagManifest),
+
+		BaseImage:     ctx.String(FlagBase),
+		BaseImageTar:  ctx.String(FlagBaseTar),
+		BaseWithCerts: ctx.Bool(FlagBaseWithCerts),
+		ExePath:       ctx.String(FlagExePath),
+
+		RegistryPush: ctx.Bool(FlagRegistryPush),
+
+		Source:       ctx.String(FlagSource),
+		BuilderImage: ctx.String(FlagBuilderImage),
+		RuntimeImage: ctx.String(FlagRuntimeImage),
+		Incremental:  ctx.Bool(FlagIncremental),
+
+		LocalRegistry: ctx.Bool(FlagLocalRegistry),
+
+		Progress: ctx.String(FlagProgress),
+
+		PullPolicy:        ctx.String(FlagPullPolicy),
+		BuilderPullPolicy: ctx.String(FlagBuilderPullPolicy),
+
+		LoadRuntimes: ctx.StringSlice(FlagRuntimeLoad),
+	}
+}
+
+// firstArch returns the architecture OnCommand should use for a single-arch
+// build (i.e. before it knows whether --architecture was repeated): the
+// first --architecture value, or the host's arch if none was given at all.
+func firstArch(architectures []string) string {
+	if len(architectures) == 0 {
+		return GetDefaultBuildArch()
+	}
+
+	return architectures[0]
+}
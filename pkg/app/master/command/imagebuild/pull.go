@@ -0,0 +1,63 @@
+package imagebuild
+
+import (
+	"fmt"
+
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// ResolveBaseImage applies cparams.PullPolicy to FlagBase before a Docker-
+// backed engine starts building, so 'never'/'always'/'if-not-present' give
+// deterministic, reproducible behavior instead of the base image being
+// silently pulled on demand. It's a no-op when the base image comes from a
+// local tar (--base-tar) since there's nothing to pull.
+func ResolveBaseImage(logger *log.Entry, dclient *docker.Client, cparams *CommandParams) error {
+	if cparams.BaseImage == "" || cparams.BaseImageTar != "" {
+		return nil
+	}
+
+	pullPolicy := cparams.PullPolicy
+	if pullPolicy == "" {
+		pullPolicy = DefaultPullPolicy
+	}
+
+	return pullImage(logger, dclient, cparams.BaseImage, pullPolicy, cparams.Architecture)
+}
+
+// pullImage resolves image against pullPolicy: 'never' fails fast if it's
+// not present locally, 'always' forces a pull, and 'if-not-present' (the
+// default) only pulls when it's missing. When a pull happens, arch (if set)
+// is passed through as the requested platform so 'always' respects
+// --architecture in a multi-arch build loop instead of pulling whatever the
+// daemon's default platform is.
+func pullImage(logger *log.Entry, dclient *docker.Client, image string, pullPolicy string, arch string) error {
+	_, inspectErr := dclient.InspectImage(image)
+	present := inspectErr == nil
+
+	switch pullPolicy {
+	case PullPolicyNever:
+		if !present {
+			return fmt.Errorf("image '%s' is not present locally and pull-policy=never", image)
+		}
+
+		return nil
+	case PullPolicyAlways:
+		//fallthrough to pull below
+	default: //if-not-present
+		if present {
+			return nil
+		}
+	}
+
+	var platform string
+	if arch != "" {
+		platform = "linux/" + arch
+	}
+
+	logger.Debugf("pulling image '%s' (pull-policy=%s, platform=%s)", image, pullPolicy, platform)
+	return dclient.PullImage(docker.PullImageOptions{
+		Repository: image,
+		Platform:   platform,
+	}, docker.AuthConfiguration{})
+}
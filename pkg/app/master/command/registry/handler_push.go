@@ -80,6 +80,13 @@ func OnPushCommand(
 		name.Insecure,
 	}
 
+	var localRegistry *registry.EphemeralRegistry
+	if cparams.LocalRegistry {
+		localRegistry, err = registry.StartEphemeralRegistry(logger, client)
+		xc.FailOn(err)
+		xc.AddCleanupHandler(func() { localRegistry.Stop() })
+	}
+
 	//todo: add support for other target types too
 	if cparams.TargetType == ttDocker {
 		tarPath, err := uniqueTarFilePath()
@@ -93,6 +100,15 @@ func OnPushCommand(
 			remoteImageName = cparams.AsTag
 		}
 
+		if localRegistry != nil {
+			remoteImageName = localRegistry.QualifyImageName(remoteImageName)
+			xc.Out.Info("local.registry",
+				ovars{
+					"address": localRegistry.Addr(),
+					"image":   remoteImageName,
+				})
+		}
+
 		err = registry.PushImageFromTar(logger, tarPath, remoteImageName, nameOpts, remoteOpts)
 		xc.FailOn(err)
 	}
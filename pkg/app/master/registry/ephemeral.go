@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const ephemeralRegistryImage = "registry:2"
+
+// EphemeralRegistry is a throwaway 'registry:2' container started on a free
+// localhost port, used so commands like 'imagebuild' and 'registry push' can
+// run end-to-end without an externally configured registry.
+type EphemeralRegistry struct {
+	ContainerID string
+	Port        int
+
+	dclient *docker.Client
+}
+
+// Addr returns the 'localhost:<port>' address the registry is listening on.
+func (ref *EphemeralRegistry) Addr() string {
+	return fmt.Sprintf("localhost:%d", ref.Port)
+}
+
+// Stop removes the ephemeral registry container.
+func (ref *EphemeralRegistry) Stop() error {
+	if ref == nil || ref.dclient == nil || ref.ContainerID == "" {
+		return nil
+	}
+
+	return ref.dclient.RemoveContainer(docker.RemoveContainerOptions{
+		ID:    ref.ContainerID,
+		Force: true,
+	})
+}
+
+// StartEphemeralRegistry starts a 'registry:2' container on a free localhost
+// port and waits for it to accept connections. Callers are responsible for
+// calling Stop() on the returned EphemeralRegistry once they're done with it.
+func StartEphemeralRegistry(logger *log.Entry, dclient *docker.Client) (*EphemeralRegistry, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, fmt.Errorf("registry.StartEphemeralRegistry: error finding a free port - %w", err)
+	}
+
+	if err := pullIfMissing(dclient, ephemeralRegistryImage); err != nil {
+		return nil, fmt.Errorf("registry.StartEphemeralRegistry: error pulling '%s' - %w", ephemeralRegistryImage, err)
+	}
+
+	container, err := dclient.CreateContainer(docker.CreateContainerOptions{
+		Config: &docker.Config{
+			Image:        ephemeralRegistryImage,
+			ExposedPorts: map[docker.Port]struct{}{"5000/tcp": {}},
+		},
+		HostConfig: &docker.HostConfig{
+			PortBindings: map[docker.Port][]docker.PortBinding{
+				"5000/tcp": {{HostIP: "127.0.0.1", HostPort: fmt.Sprintf("%d", port)}},
+			},
+			AutoRemove: true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("registry.StartEphemeralRegistry: error creating registry container - %w", err)
+	}
+
+	ref := &EphemeralRegistry{
+		ContainerID: container.ID,
+		Port:        port,
+		dclient:     dclient,
+	}
+
+	if err := dclient.StartContainer(container.ID, nil); err != nil {
+		return nil, fmt.Errorf("registry.StartEphemeralRegistry: error starting registry container - %w", err)
+	}
+
+	if err := waitForPort(ref.Addr(), 30*time.Second); err != nil {
+		ref.Stop()
+		return nil, fmt.Errorf("registry.StartEphemeralRegistry: registry did not become ready - %w", err)
+	}
+
+	logger.Debugf("registry.StartEphemeralRegistry: started '%s' on %s", container.ID, ref.Addr())
+	return ref, nil
+}
+
+func pullIfMissing(dclient *docker.Client, image string) error {
+	if _, err := dclient.InspectImage(image); err == nil {
+		return nil
+	}
+
+	return dclient.PullImage(docker.PullImageOptions{Repository: image}, docker.AuthConfiguration{})
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func waitForPort(addr string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return fmt.Errorf("timed out waiting for %s", addr)
+}
+
+// QualifyImageName rewrites imageName to be hosted by this ephemeral registry
+// if it isn't already qualified with a registry host.
+func (ref *EphemeralRegistry) QualifyImageName(imageName string) string {
+	// Only a first path segment can name a registry host; a name with no '/'
+	// (even 'name:tag') is always unqualified.
+	idx := strings.Index(imageName, "/")
+	if idx == -1 {
+		return fmt.Sprintf("%s/%s", ref.Addr(), imageName)
+	}
+
+	firstSegment := imageName[:idx]
+	if strings.Contains(firstSegment, ".") || strings.Contains(firstSegment, ":") || firstSegment == "localhost" {
+		return imageName
+	}
+
+	return fmt.Sprintf("%s/%s", ref.Addr(), imageName)
+}
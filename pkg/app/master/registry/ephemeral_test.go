@@ -0,0 +1,47 @@
+package registry
+
+import "testing"
+
+func TestQualifyImageName(t *testing.T) {
+	ref := &EphemeralRegistry{Port: 5000}
+
+	testCases := []struct {
+		name      string
+		imageName string
+		want      string
+	}{
+		{
+			name:      "unqualified name gets the ephemeral registry host",
+			imageName: "myapp",
+			want:      "localhost:5000/myapp",
+		},
+		{
+			name:      "unqualified name with a path gets the ephemeral registry host",
+			imageName: "org/myapp",
+			want:      "localhost:5000/org/myapp",
+		},
+		{
+			name:      "already host-qualified name is left alone",
+			imageName: "registry.example.com/org/myapp",
+			want:      "registry.example.com/org/myapp",
+		},
+		{
+			name:      "host:port-qualified name is left alone",
+			imageName: "example.com:5001/org/myapp",
+			want:      "example.com:5001/org/myapp",
+		},
+		{
+			name:      "localhost-qualified name is left alone",
+			imageName: "localhost/myapp",
+			want:      "localhost/myapp",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ref.QualifyImageName(tc.imageName); got != tc.want {
+				t.Errorf("QualifyImageName(%q) = %q, want %q", tc.imageName, got, tc.want)
+			}
+		})
+	}
+}
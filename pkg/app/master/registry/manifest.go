@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	log "github.com/sirupsen/logrus"
+)
+
+// PushManifestList pushes a set of per-architecture image tar archives as a
+// single OCI Image Index (a.k.a. fat/multi-arch manifest) under the given
+// name. When appendExisting is true and the manifest already exists in the target
+// registry, the per-arch images are added to the existing index instead of
+// replacing it.
+func PushManifestList(
+	logger *log.Entry,
+	archImages map[string]string,
+	manifestName string,
+	appendExisting bool,
+	nameOpts []name.Option,
+	remoteOpts []remote.Option) error {
+	ref, err := name.ParseReference(manifestName, nameOpts...)
+	if err != nil {
+		return fmt.Errorf("registry.PushManifestList: bad manifest reference '%s' - %w", manifestName, err)
+	}
+
+	idx := v1.ImageIndex(empty.Index)
+	if appendExisting {
+		if existing, ierr := remote.Index(ref, remoteOpts...); ierr == nil {
+			idx = existing
+			logger.Debugf("registry.PushManifestList: appending to existing manifest list '%s'", manifestName)
+		} else {
+			logger.WithError(ierr).Debug("registry.PushManifestList: no existing manifest list found, creating a new one")
+		}
+	}
+
+	for arch, archiveFile := range archImages {
+		img, err := tarball.ImageFromPath(archiveFile, nil)
+		if err != nil {
+			return fmt.Errorf("registry.PushManifestList: error loading image archive '%s' (arch=%s) - %w", archiveFile, arch, err)
+		}
+
+		platform := v1.Platform{OS: "linux", Architecture: arch}
+		// Drop any entry already in the index for this os/arch first, so
+		// re-running the same multi-arch build against an existing
+		// --manifest updates that platform's image instead of piling up
+		// duplicate entries for it.
+		idx = mutate.RemoveManifests(idx, match.Platforms(platform))
+
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &platform,
+			},
+		})
+	}
+
+	if err := remote.WriteIndex(ref, idx, remoteOpts...); err != nil {
+		return fmt.Errorf("registry.PushManifestList: error pushing manifest list '%s' - %w", manifestName, err)
+	}
+
+	logger.Debugf("registry.PushManifestList: pushed manifest list '%s' with %d architectures", manifestName, len(archImages))
+	return nil
+}
@@ -0,0 +1,29 @@
+package notify
+
+import "encoding/json"
+
+// discordSink posts to a Discord webhook URL.
+type discordSink struct {
+	url    string
+	events []string
+}
+
+func newDiscordSink(sc SinkConfig) *discordSink {
+	return &discordSink{url: sc.URL, events: sc.Events}
+}
+
+func (s *discordSink) Name() string     { return "discord:" + s.url }
+func (s *discordSink) Events() []string { return s.events }
+
+func (s *discordSink) Notify(e Event) error {
+	payload := map[string]string{
+		"content": formatEvent(e),
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(s.url, data)
+}
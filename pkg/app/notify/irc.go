@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// ircSink is a minimal IRC client: connect, register, join, PRIVMSG, quit.
+// It intentionally doesn't pull in a full IRC library since all it needs is
+// a single fire-and-forget message per event.
+type ircSink struct {
+	server  string
+	channel string
+	nick    string
+	events  []string
+}
+
+func newIRCSink(sc SinkConfig) *ircSink {
+	nick := sc.Nick
+	if nick == "" {
+		nick = "mint-bot"
+	}
+
+	return &ircSink{server: sc.Server, channel: sc.Channel, nick: nick, events: sc.Events}
+}
+
+func (s *ircSink) Name() string     { return "irc:" + s.server + s.channel }
+func (s *ircSink) Events() []string { return s.events }
+
+func (s *ircSink) Notify(e Event) error {
+	conn, err := net.DialTimeout("tcp", s.server, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	lines := []string{
+		fmt.Sprintf("NICK %s", s.nick),
+		fmt.Sprintf("USER %s 0 * :mint notifier", s.nick),
+		fmt.Sprintf("JOIN %s", s.channel),
+		fmt.Sprintf("PRIVMSG %s :%s", s.channel, formatEvent(e)),
+		"QUIT",
+	}
+
+	for _, line := range lines {
+		if _, err := conn.Write([]byte(line + "\r\n")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
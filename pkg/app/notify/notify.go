@@ -0,0 +1,177 @@
+// Package notify lets mint push Fail/Exit events to external sinks (a
+// generic webhook, Slack, Discord, or IRC) instead of only printing them
+// locally, so a CI operator or team channel can subscribe to long-running
+// slim/build failures.
+package notify
+
+import (
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Event is the payload delivered to every sink. It reuses the same fields
+// Output.State/Info already stamp into their `msg` maps, plus a stack trace
+// that's only populated on Fail.
+type Event struct {
+	Cmd      string                 `json:"cmd"`
+	Kind     string                 `json:"kind"` // "fail" or "exit"
+	State    string                 `json:"state,omitempty"`
+	ExitCode int                    `json:"exit.code"`
+	Version  string                 `json:"version"`
+	Args     []string               `json:"args,omitempty"`
+	Location string                 `json:"location,omitempty"`
+	Stack    string                 `json:"stack,omitempty"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Wants reports whether this event should be delivered to a sink subscribed
+// to the given event filters ("fail", "exit", "state=exited", "error.*").
+func (e Event) Wants(filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	for _, f := range filters {
+		switch {
+		case f == e.Kind:
+			return true
+		case f == "state="+e.State && e.State != "":
+			return true
+		case f == "error.*" && e.Kind == "fail":
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sink delivers Events to an external system.
+type Sink interface {
+	Name() string
+	Events() []string
+	Notify(e Event) error
+}
+
+// SinkConfig is one entry of notify.yaml / MINT_NOTIFY_* env config.
+type SinkConfig struct {
+	Type   string   `yaml:"type"` // webhook, slack, discord, irc
+	URL    string   `yaml:"url,omitempty"`
+	Events []string `yaml:"events,omitempty"`
+
+	//irc-only
+	Server  string `yaml:"server,omitempty"`
+	Channel string `yaml:"channel,omitempty"`
+	Nick    string `yaml:"nick,omitempty"`
+}
+
+// Config is the parsed notify.yaml.
+type Config struct {
+	Sinks []SinkConfig `yaml:"sinks"`
+}
+
+// ConfigPath returns the default config file location ($HOME/.mint/notify.yaml).
+func ConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".mint", "notify.yaml")
+}
+
+// LoadConfig reads sinks from path (ignoring a missing file) and appends any
+// sinks implied by MINT_NOTIFY_* environment variables.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+
+	if data, err := os.ReadFile(path); err == nil {
+		if uerr := yaml.Unmarshal(data, &cfg); uerr != nil {
+			return cfg, uerr
+		}
+	} else if !os.IsNotExist(err) {
+		return cfg, err
+	}
+
+	cfg.Sinks = append(cfg.Sinks, sinksFromEnv()...)
+	return cfg, nil
+}
+
+func sinksFromEnv() []SinkConfig {
+	var sinks []SinkConfig
+
+	if u := os.Getenv("MINT_NOTIFY_WEBHOOK_URL"); u != "" {
+		sinks = append(sinks, SinkConfig{Type: "webhook", URL: u})
+	}
+
+	if u := os.Getenv("MINT_NOTIFY_SLACK_URL"); u != "" {
+		sinks = append(sinks, SinkConfig{Type: "slack", URL: u})
+	}
+
+	if u := os.Getenv("MINT_NOTIFY_DISCORD_URL"); u != "" {
+		sinks = append(sinks, SinkConfig{Type: "discord", URL: u})
+	}
+
+	if server := os.Getenv("MINT_NOTIFY_IRC_SERVER"); server != "" {
+		sinks = append(sinks, SinkConfig{
+			Type:    "irc",
+			Server:  server,
+			Channel: os.Getenv("MINT_NOTIFY_IRC_CHANNEL"),
+			Nick:    os.Getenv("MINT_NOTIFY_IRC_NICK"),
+		})
+	}
+
+	return sinks
+}
+
+// NewSinks builds the live Sink for every configured SinkConfig, skipping
+// (and logging) any entry with an unknown type.
+func NewSinks(cfg Config) []Sink {
+	var sinks []Sink
+	for _, sc := range cfg.Sinks {
+		switch sc.Type {
+		case "webhook":
+			sinks = append(sinks, newWebhookSink(sc))
+		case "slack":
+			sinks = append(sinks, newSlackSink(sc))
+		case "discord":
+			sinks = append(sinks, newDiscordSink(sc))
+		case "irc":
+			sinks = append(sinks, newIRCSink(sc))
+		default:
+			log.Warnf("notify: unknown sink type '%s'", sc.Type)
+		}
+	}
+
+	return sinks
+}
+
+// Notifier fans an Event out to every configured Sink that wants it,
+// logging (but not failing on) delivery errors.
+type Notifier struct {
+	sinks []Sink
+}
+
+// NewNotifier loads config from path and from MINT_NOTIFY_* env vars.
+func NewNotifier(path string) *Notifier {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		log.WithError(err).Debug("notify: error loading config")
+	}
+
+	return &Notifier{sinks: NewSinks(cfg)}
+}
+
+// Dispatch sends e to every sink interested in it.
+func (n *Notifier) Dispatch(e Event) {
+	if n == nil {
+		return
+	}
+
+	for _, s := range n.sinks {
+		if !e.Wants(s.Events()) {
+			continue
+		}
+
+		if err := s.Notify(e); err != nil {
+			log.WithError(err).WithField("sink", s.Name()).Debug("notify: delivery error")
+		}
+	}
+}
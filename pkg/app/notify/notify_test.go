@@ -0,0 +1,63 @@
+package notify
+
+import "testing"
+
+func TestEventWants(t *testing.T) {
+	testCases := []struct {
+		name    string
+		event   Event
+		filters []string
+		want    bool
+	}{
+		{
+			name:    "no filters matches everything",
+			event:   Event{Kind: "fail"},
+			filters: nil,
+			want:    true,
+		},
+		{
+			name:    "kind match",
+			event:   Event{Kind: "exit"},
+			filters: []string{"exit"},
+			want:    true,
+		},
+		{
+			name:    "kind mismatch",
+			event:   Event{Kind: "fail"},
+			filters: []string{"exit"},
+			want:    false,
+		},
+		{
+			name:    "state filter match",
+			event:   Event{Kind: "exit", State: "exited"},
+			filters: []string{"state=exited"},
+			want:    true,
+		},
+		{
+			name:    "state filter requires non-empty state",
+			event:   Event{Kind: "exit"},
+			filters: []string{"state="},
+			want:    false,
+		},
+		{
+			name:    "error.* matches any fail",
+			event:   Event{Kind: "fail"},
+			filters: []string{"error.*"},
+			want:    true,
+		},
+		{
+			name:    "error.* does not match exit",
+			event:   Event{Kind: "exit"},
+			filters: []string{"error.*"},
+			want:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.event.Wants(tc.filters); got != tc.want {
+				t.Errorf("Wants(%v) = %v, want %v", tc.filters, got, tc.want)
+			}
+		})
+	}
+}
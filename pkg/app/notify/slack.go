@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// slackSink posts to a Slack incoming webhook URL.
+type slackSink struct {
+	url    string
+	events []string
+}
+
+func newSlackSink(sc SinkConfig) *slackSink {
+	return &slackSink{url: sc.URL, events: sc.Events}
+}
+
+func (s *slackSink) Name() string     { return "slack:" + s.url }
+func (s *slackSink) Events() []string { return s.events }
+
+func (s *slackSink) Notify(e Event) error {
+	payload := map[string]string{
+		"text": formatEvent(e),
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(s.url, data)
+}
+
+func formatEvent(e Event) string {
+	if e.Kind == "fail" {
+		return fmt.Sprintf("[mint] cmd=%s FAILED version=%s location=%s", e.Cmd, e.Version, e.Location)
+	}
+
+	return fmt.Sprintf("[mint] cmd=%s exited code=%d version=%s location=%s", e.Cmd, e.ExitCode, e.Version, e.Location)
+}
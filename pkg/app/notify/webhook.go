@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookSink posts the raw Event as JSON to a generic URL.
+type webhookSink struct {
+	url    string
+	events []string
+}
+
+func newWebhookSink(sc SinkConfig) *webhookSink {
+	return &webhookSink{url: sc.URL, events: sc.Events}
+}
+
+func (s *webhookSink) Name() string     { return "webhook:" + s.url }
+func (s *webhookSink) Events() []string { return s.events }
+
+func (s *webhookSink) Notify(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(s.url, data)
+}
+
+func postJSON(url string, data []byte) error {
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notify: sink '%s' returned status %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package subscribe serves the live events of an app.Output over JSON-RPC
+// 2.0, so external tools (dashboards, CI plugins, IDE integrations) can
+// attach to a running mint command instead of scraping its stdout JSON.
+package subscribe
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mintoolkit/mint/pkg/app"
+)
+
+// Supported JSON-RPC methods.
+const (
+	MethodSubscribe   = "mint.subscribe"
+	MethodUnsubscribe = "mint.unsubscribe"
+	MethodReplayLast  = "mint.replayLast"
+	MethodGetState    = "mint.getState"
+)
+
+// request is a JSON-RPC 2.0 request/notification.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC 2.0 response (used for request replies) or, with no
+// ID, a server-initiated notification.
+type response struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      interface{} `json:"id,omitempty"`
+	Method  string      `json:"method,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+	Params  interface{} `json:"params,omitempty"`
+	Error   *rpcError   `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type subscribeParams struct {
+	Topics []string `json:"topics"`
+	// Drop selects the backpressure policy applied to this subscription
+	// when the client falls behind: "oldest" (default) discards the
+	// oldest buffered event to make room for the new one, "newest" drops
+	// the incoming event instead so already-buffered history is kept.
+	Drop string `json:"drop"`
+}
+
+func dropPolicy(name string) app.DropPolicy {
+	if name == "newest" {
+		return app.DropNewest
+	}
+
+	return app.DropOldest
+}
+
+type replayParams struct {
+	Topic string `json:"topic"`
+	N     int    `json:"n"`
+}
+
+// AttachRPC accepts connections on listener and serves the JSON-RPC 2.0
+// subscription protocol against out's Broker. It blocks until listener is
+// closed, so callers typically run it in its own goroutine.
+func AttachRPC(out *app.Output, listener net.Listener, logger *log.Entry) error {
+	if logger == nil {
+		logger = log.WithField("component", "subscribe")
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go serveConn(out, conn, logger)
+	}
+}
+
+// serveConn handles one client: newline-delimited JSON-RPC requests in,
+// newline-delimited JSON-RPC responses/notifications out (this also happens
+// to be how a WebSocket text-frame reader/writer pair can be wired in, by
+// substituting conn for the frame reader/writer on the caller side).
+func serveConn(out *app.Output, conn net.Conn, logger *log.Entry) {
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	enc := json.NewEncoder(conn)
+	write := func(msg response) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := enc.Encode(msg); err != nil {
+			logger.WithError(err).Debug("subscribe: write error")
+		}
+	}
+
+	var subsMu sync.Mutex
+	subs := map[string]*app.Subscription{}
+
+	defer func() {
+		subsMu.Lock()
+		defer subsMu.Unlock()
+		for id := range subs {
+			out.Broker.Unsubscribe(id)
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			write(response{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "parse error"}})
+			continue
+		}
+
+		switch req.Method {
+		case MethodSubscribe:
+			var params subscribeParams
+			_ = json.Unmarshal(req.Params, &params)
+
+			sub := out.Broker.Subscribe(params.Topics, dropPolicy(params.Drop))
+
+			subsMu.Lock()
+			subs[sub.ID] = sub
+			subsMu.Unlock()
+
+			go pump(sub, write)
+
+			write(response{JSONRPC: "2.0", ID: req.ID, Result: map[string]string{"subscriptionId": sub.ID}})
+		case MethodUnsubscribe:
+			var params struct {
+				SubscriptionID string `json:"subscriptionId"`
+			}
+			_ = json.Unmarshal(req.Params, &params)
+
+			subsMu.Lock()
+			_, ok := subs[params.SubscriptionID]
+			delete(subs, params.SubscriptionID)
+			subsMu.Unlock()
+
+			if ok {
+				out.Broker.Unsubscribe(params.SubscriptionID)
+			}
+
+			write(response{JSONRPC: "2.0", ID: req.ID, Result: ok})
+		case MethodReplayLast:
+			var params replayParams
+			_ = json.Unmarshal(req.Params, &params)
+
+			events := out.Broker.ReplayLast(params.Topic, params.N)
+			write(response{JSONRPC: "2.0", ID: req.ID, Result: events})
+		case MethodGetState:
+			write(response{JSONRPC: "2.0", ID: req.ID, Result: out.Broker.GetState()})
+		default:
+			write(response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: -32601, Message: "method not found"}})
+		}
+	}
+}
+
+// pump forwards a Subscription's events to the client as JSON-RPC
+// notifications until the Subscription's channel is closed.
+func pump(sub *app.Subscription, write func(response)) {
+	for ev := range sub.Ch {
+		write(response{
+			JSONRPC: "2.0",
+			Method:  "mint.event",
+			Params: map[string]interface{}{
+				"subscriptionId": sub.ID,
+				"topic":          ev.Topic,
+				"data":           ev.Data,
+			},
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package theme maps the semantic roles Output colorizes (info type, key,
+// value, prompt, error, message, state-ok, state-error, community) to
+// color.Attribute lists, so users can override mint's hard-coded colors
+// without patching the binary.
+package theme
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// Role is a semantic spot in the CLI output that gets colorized.
+type Role string
+
+const (
+	RoleInfoType   Role = "info-type"
+	RoleKey        Role = "key"
+	RoleValue      Role = "value"
+	RolePrompt     Role = "prompt"
+	RoleError      Role = "error"
+	RoleMessage    Role = "message"
+	RoleStateOK    Role = "state-ok"
+	RoleStateError Role = "state-error"
+	RoleCommunity  Role = "community"
+)
+
+// EnvTheme names the environment variable that selects a built-in theme by
+// name (overridden by the --theme CLI flag where one is wired up).
+const EnvTheme = "MINT_THEME"
+
+// ConfigRelPath is where a user theme file is looked up under
+// $XDG_CONFIG_HOME (or $HOME/.config as a fallback).
+const ConfigRelPath = "mint/theme.yaml"
+
+// Theme is a name plus a role -> color.Attribute list mapping. Attrs(role)
+// always returns a usable attribute list, falling back to the default
+// theme's for any role the theme doesn't override.
+type Theme struct {
+	Name  string            `json:"name" yaml:"name"`
+	Roles map[Role][]string `json:"roles" yaml:"roles"`
+	attrs map[Role][]color.Attribute
+}
+
+// Attrs returns the color.Attribute list for role, falling back to the
+// default theme if this theme doesn't define one.
+func (t *Theme) Attrs(role Role) []color.Attribute {
+	if t != nil {
+		if attrs, ok := t.attrs[role]; ok {
+			return attrs
+		}
+	}
+
+	return Default().attrs[role]
+}
+
+// Set applies role's colors via color.Set; callers must `defer color.Unset()`.
+func (t *Theme) Set(role Role) {
+	color.Set(t.Attrs(role)...)
+}
+
+// Sprint renders args with role's colors, matching color.New(...).Sprint.
+func (t *Theme) Sprint(role Role, a ...interface{}) string {
+	return color.New(t.Attrs(role)...).Sprint(a...)
+}
+
+// Sprintf renders format/args with role's colors, matching
+// color.New(...).Sprintf.
+func (t *Theme) Sprintf(role Role, format string, a ...interface{}) string {
+	return color.New(t.Attrs(role)...).Sprintf(format, a...)
+}
+
+func named(name string, roles map[Role][]string) *Theme {
+	t := &Theme{Name: name, Roles: roles}
+	t.compile()
+	return t
+}
+
+func (t *Theme) compile() {
+	t.attrs = make(map[Role][]color.Attribute, len(t.Roles))
+	for role, names := range t.Roles {
+		attrs := make([]color.Attribute, 0, len(names))
+		for _, n := range names {
+			if a, ok := attrByName[n]; ok {
+				attrs = append(attrs, a)
+			}
+		}
+
+		t.attrs[role] = attrs
+	}
+}
+
+var attrByName = map[string]color.Attribute{
+	"bold":       color.Bold,
+	"faint":      color.Faint,
+	"underline":  color.Underline,
+	"black":      color.FgBlack,
+	"red":        color.FgRed,
+	"green":      color.FgGreen,
+	"yellow":     color.FgYellow,
+	"blue":       color.FgBlue,
+	"magenta":    color.FgMagenta,
+	"cyan":       color.FgCyan,
+	"white":      color.FgWhite,
+	"hi-black":   color.FgHiBlack,
+	"hi-red":     color.FgHiRed,
+	"hi-green":   color.FgHiGreen,
+	"hi-yellow":  color.FgHiYellow,
+	"hi-blue":    color.FgHiBlue,
+	"hi-magenta": color.FgHiMagenta,
+	"hi-cyan":    color.FgHiCyan,
+	"hi-white":   color.FgHiWhite,
+}
+
+// builtins mirrors mint's historic hard-coded colors as the 'default' theme,
+// plus a handful of alternates for different terminal backgrounds and
+// accessibility needs.
+var builtins = map[string]*Theme{
+	"default": named("default", map[Role][]string{
+		RoleInfoType:   {"magenta", "bold"},
+		RoleKey:        {"hi-green", "bold"},
+		RoleValue:      {"hi-blue"},
+		RolePrompt:     {"hi-red"},
+		RoleError:      {"hi-red"},
+		RoleMessage:    {"hi-magenta"},
+		RoleStateOK:    {"cyan", "bold"},
+		RoleStateError: {"hi-red", "bold"},
+		RoleCommunity:  {"hi-magenta"},
+	}),
+	"dark": named("dark", map[Role][]string{
+		RoleInfoType:   {"hi-magenta", "bold"},
+		RoleKey:        {"hi-green", "bold"},
+		RoleValue:      {"hi-cyan"},
+		RolePrompt:     {"hi-yellow"},
+		RoleError:      {"hi-red", "bold"},
+		RoleMessage:    {"hi-magenta"},
+		RoleStateOK:    {"hi-cyan", "bold"},
+		RoleStateError: {"hi-red", "bold"},
+		RoleCommunity:  {"hi-magenta"},
+	}),
+	"light": named("light", map[Role][]string{
+		RoleInfoType:   {"magenta", "bold"},
+		RoleKey:        {"green", "bold"},
+		RoleValue:      {"blue"},
+		RolePrompt:     {"red"},
+		RoleError:      {"red", "bold"},
+		RoleMessage:    {"magenta"},
+		RoleStateOK:    {"cyan", "bold"},
+		RoleStateError: {"red", "bold"},
+		RoleCommunity:  {"magenta"},
+	}),
+	"mono": named("mono", map[Role][]string{
+		RoleInfoType:   {"bold"},
+		RoleKey:        {"bold"},
+		RoleValue:      {},
+		RolePrompt:     {"bold"},
+		RoleError:      {"bold", "underline"},
+		RoleMessage:    {},
+		RoleStateOK:    {"bold"},
+		RoleStateError: {"bold", "underline"},
+		RoleCommunity:  {},
+	}),
+	"solarized": named("solarized", map[Role][]string{
+		RoleInfoType:   {"yellow", "bold"},
+		RoleKey:        {"green", "bold"},
+		RoleValue:      {"cyan"},
+		RolePrompt:     {"magenta"},
+		RoleError:      {"red", "bold"},
+		RoleMessage:    {"blue"},
+		RoleStateOK:    {"cyan", "bold"},
+		RoleStateError: {"red", "bold"},
+		RoleCommunity:  {"blue"},
+	}),
+}
+
+// Default returns mint's historic color scheme.
+func Default() *Theme {
+	return builtins["default"]
+}
+
+// Builtin looks up one of the themes mint ships out of the box
+// ('default', 'dark', 'light', 'mono', 'solarized').
+func Builtin(name string) (*Theme, bool) {
+	t, ok := builtins[name]
+	return t, ok
+}
+
+// Load reads a theme from a YAML or JSON file, selected by its extension.
+func Load(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Theme{}
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(data, t)
+	default: //.yaml, .yml
+		err = yaml.Unmarshal(data, t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.compile()
+	return t, nil
+}
+
+// ConfigPath returns the default per-user theme file location
+// ($XDG_CONFIG_HOME/mint/theme.yaml, falling back to $HOME/.config).
+func ConfigPath() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		base = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+
+	return filepath.Join(base, ConfigRelPath)
+}
+
+// Resolve picks the active theme: an explicit --theme name/path wins, then
+// MINT_THEME, then the user's config file, then the built-in default.
+// themeFlag may be a built-in name or a path to a theme file.
+func Resolve(themeFlag string) *Theme {
+	candidate := themeFlag
+	if candidate == "" {
+		candidate = os.Getenv(EnvTheme)
+	}
+
+	if candidate != "" {
+		if t, ok := Builtin(candidate); ok {
+			return t
+		}
+
+		if t, err := Load(candidate); err == nil {
+			return t
+		}
+	}
+
+	if t, err := Load(ConfigPath()); err == nil {
+		return t
+	}
+
+	return Default()
+}
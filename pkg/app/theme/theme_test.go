@@ -0,0 +1,33 @@
+package theme
+
+import "testing"
+
+func TestThemeAttrsFallsBackToDefault(t *testing.T) {
+	mono, ok := Builtin("mono")
+	if !ok {
+		t.Fatal("builtin theme 'mono' not found")
+	}
+
+	// 'mono' uses different attrs than 'default', but Attrs should still
+	// return a non-nil list for a role the theme defines.
+	if attrs := mono.Attrs(RoleInfoType); attrs == nil {
+		t.Error("Attrs(RoleInfoType) = nil, want a non-nil attribute list")
+	}
+
+	// A role the theme has no entry for at all falls back to Default()'s.
+	unknownRole := Role("does-not-exist")
+	got := mono.Attrs(unknownRole)
+	want := Default().Attrs(unknownRole)
+	if len(got) != len(want) {
+		t.Errorf("Attrs(%q) = %v, want fallback to Default() = %v", unknownRole, got, want)
+	}
+}
+
+func TestThemeAttrsNilReceiverFallsBackToDefault(t *testing.T) {
+	var t1 *Theme
+	got := t1.Attrs(RoleError)
+	want := Default().Attrs(RoleError)
+	if len(got) != len(want) {
+		t.Errorf("nil Theme.Attrs(RoleError) = %v, want Default()'s %v", got, want)
+	}
+}